@@ -3,6 +3,7 @@ module github.com/terra-discover/bbcrs-helper-lib
 go 1.22
 
 require (
+	github.com/andybalholm/brotli v1.0.5
 	github.com/bytedance/sonic v1.9.0
 	github.com/getsentry/sentry-go v0.31.1
 	github.com/go-openapi/strfmt v0.20.2
@@ -22,7 +23,6 @@ require (
 )
 
 require (
-	github.com/andybalholm/brotli v1.0.5 // indirect
 	github.com/asaskevich/govalidator v0.0.0-20200907205600-7a23bdc65eef // indirect
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/chenzhuoyu/base64x v0.0.0-20221115062448-fe3a3abad311 // indirect
@@ -0,0 +1,383 @@
+package lib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// errBodyTooLarge decoded body exceeds BindOptions.MaxBodySize
+var errBodyTooLarge = errors.New("request body too large")
+
+// BindOptions Bind options
+type BindOptions struct {
+	Strict      bool   // unknown json body fields are rejected
+	MaxBodySize int    // maximum body size in bytes, 0 is unlimited
+	ContentType string // required body content type, example: application/json
+}
+
+// Bind bind path params, query, headers and body into payload then sanitize and validate it,
+// body fields are bound by json / form tags, query, header and path values are bound only into fields
+// which declare the query, header or params tag, path params win over the other sources
+//
+//	=> Example
+//	type UpdateBooking struct {
+//		ID        uuid.UUID `params:"id" validate:"required"`
+//		Notify    bool      `query:"notify"`
+//		RequestID string    `header:"X-Request-ID"`
+//		Note      string    `json:"note" validate:"max=200"`
+//	}
+//	payload := UpdateBooking{}
+//	if err := lib.Bind(c, &payload, lib.BindOptions{Strict: true, ContentType: fiber.MIMEApplicationJSON}); nil != err {
+//		return err // sent by lib.ErrorHandler, or lib.ErrorBadRequest(c, err)
+//	}
+func Bind(c *fiber.Ctx, payload interface{}, options ...BindOptions) error {
+	opts := BindOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	if raw := c.Request().Body(); len(raw) > 0 {
+		contentType := utils.ParseVendorSpecificContentType(utils.ToLower(string(c.Request().Header.ContentType())))
+		if opts.ContentType != "" && !strings.HasPrefix(contentType, utils.ToLower(opts.ContentType)) {
+			return SetErrorUnsupportedMediaType("Content-Type must be " + opts.ContentType)
+		}
+		// the limit is checked on the compressed body before it is decoded
+		tooLarge := SetErrorRequestEntityTooLarge(fmt.Sprintf("Request body must not exceed %d bytes", opts.MaxBodySize))
+		if opts.MaxBodySize > 0 && (c.Request().Header.ContentLength() > opts.MaxBodySize || len(raw) > opts.MaxBodySize) {
+			return tooLarge
+		}
+
+		body, err := decodeBody(c, opts.MaxBodySize)
+		if errors.Is(err, errBodyTooLarge) {
+			return tooLarge
+		}
+		if nil != err {
+			return SetErrorBadRequest("Invalid request body encoding").WithCause(err)
+		}
+
+		isJSON := strings.HasPrefix(contentType, fiber.MIMEApplicationJSON)
+		if opts.Strict && isJSON {
+			if errs := unknownBodyFields(c, body, payload); len(errs) > 0 {
+				return SetErrorBadRequest("Request body contains unknown fields").WithErrorData(errs...)
+			}
+		}
+		if err := c.BodyParser(payload); nil != err {
+			return bindBodyError(c, err)
+		}
+	}
+
+	query := func(key string) []string {
+		values := []string{}
+		for _, value := range c.Context().QueryArgs().PeekMulti(key) {
+			values = append(values, string(value))
+		}
+		return values
+	}
+	if err := bindValues(c, reflect.ValueOf(payload), "query", query, "Invalid query parameters"); nil != err {
+		return err
+	}
+	header := func(key string) []string {
+		if value := c.Get(key); value != "" {
+			return []string{value}
+		}
+		return nil
+	}
+	if err := bindValues(c, reflect.ValueOf(payload), "header", header, "Invalid request headers"); nil != err {
+		return err
+	}
+	params := func(key string) []string {
+		if value := c.Params(key); value != "" {
+			return []string{value}
+		}
+		return nil
+	}
+	if err := bindValues(c, reflect.ValueOf(payload), "params", params, "Invalid path parameters"); nil != err {
+		return err
+	}
+	if err := Sanitize(payload); nil != err {
+		return err
+	}
+
+	return VALIDATOR.Struct(payload)
+}
+
+// decodeBody request body decoded by Content-Encoding, the decoded body replaces the raw body so it is decoded once,
+// decoding stops with errBodyTooLarge as soon as limit is exceeded so compressed bombs are never fully expanded
+func decodeBody(c *fiber.Ctx, limit int) ([]byte, error) {
+	raw := bytes.NewReader(c.Request().Body())
+	var reader io.Reader
+	var err error
+	switch utils.ToLower(c.Get(fiber.HeaderContentEncoding)) {
+	case "":
+		return c.Request().Body(), nil
+	case fiber.StrGzip:
+		reader, err = gzip.NewReader(raw)
+	case fiber.StrBr, fiber.StrBrotli:
+		reader = brotli.NewReader(raw)
+	case fiber.StrDeflate:
+		reader, err = zlib.NewReader(raw)
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %s", c.Get(fiber.HeaderContentEncoding))
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	if limit > 0 {
+		reader = io.LimitReader(reader, int64(limit)+1)
+	}
+	body, err := io.ReadAll(reader)
+	if nil != err {
+		return nil, err
+	}
+	if limit > 0 && len(body) > limit {
+		return nil, errBodyTooLarge
+	}
+
+	c.Request().Header.Del(fiber.HeaderContentEncoding)
+	c.Request().SetBodyRaw(body)
+	return body, nil
+}
+
+// bindBodyError body decoding error, json type errors are reported as error data
+func bindBodyError(c *fiber.Ctx, err error) error {
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) {
+		path := typeError.Field
+		name := path
+		if index := strings.LastIndex(path, "."); index >= 0 {
+			name = path[index+1:]
+		}
+		errorData := ErrorData{Name: name, Path: path, Type: typeError.Value, Validator: "type", Criteria: typeError.Type.String()}
+		errorData.Message = ValidationMessage(GetLanguage(c), errorData)
+		return SetErrorBadRequest("Request body does not meet the requirements").WithErrorData(errorData).WithCause(err)
+	}
+
+	return SetErrorBadRequest("Invalid request body").WithCause(err)
+}
+
+// unknownBodyFields json body fields which are not declared by payload
+func unknownBodyFields(c *fiber.Ctx, body []byte, payload interface{}) []ErrorData {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); nil != err {
+		return nil // syntax errors are reported by the body parser
+	}
+
+	paths := unknownFields(reflect.TypeOf(payload), document, "")
+	sort.Strings(paths)
+
+	errs := []ErrorData{}
+	for _, path := range paths {
+		name := path
+		if index := strings.LastIndexAny(path, ".]"); index >= 0 {
+			name = path[index+1:]
+		}
+		errorData := ErrorData{Name: name, Path: path, Validator: "unknown_field"}
+		errorData.Message = ValidationMessage(GetLanguage(c), errorData)
+		errs = append(errs, errorData)
+	}
+	return errs
+}
+
+// unknownFields paths of document members which have no matching json field in typ
+func unknownFields(typ reflect.Type, document interface{}, path string) []string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) || reflect.PointerTo(typ).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return nil // custom decoding
+	}
+
+	unknown := []string{}
+	switch value := document.(type) {
+	case map[string]interface{}:
+		if typ.Kind() != reflect.Struct {
+			return nil // maps and interfaces accept any member
+		}
+		fields := jsonFields(typ)
+		for key, member := range value {
+			field, ok := fields[key]
+			if !ok {
+				field, ok = fields[strings.ToLower(key)] // encoding/json matches case insensitively
+			}
+			memberPath := key
+			if path != "" {
+				memberPath = path + "." + key
+			}
+			if !ok {
+				unknown = append(unknown, memberPath)
+				continue
+			}
+			unknown = append(unknown, unknownFields(field.Type, member, memberPath)...)
+		}
+	case []interface{}:
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+			return nil
+		}
+		for i, item := range value {
+			unknown = append(unknown, unknownFields(typ.Elem(), item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return unknown
+}
+
+// jsonFields json fields of struct type keyed by json name and its lowercase, embedded struct fields are promoted
+func jsonFields(typ reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for key, promoted := range jsonFields(fieldType) {
+				if _, ok := fields[key]; !ok {
+					fields[key] = promoted
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+		if _, ok := fields[strings.ToLower(name)]; !ok {
+			fields[strings.ToLower(name)] = field
+		}
+	}
+	return fields
+}
+
+// bindValues set fields which declare the tag from values of the source, untagged fields are never bound,
+// embedded structs are walked
+func bindValues(c *fiber.Ctx, value reflect.Value, tag string, source func(key string) []string, message string) error {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key, ok := field.Tag.Lookup(tag)
+		if !ok && field.Anonymous && value.Field(i).Kind() == reflect.Struct {
+			if err := bindValues(c, value.Field(i), tag, source, message); nil != err {
+				return err
+			}
+			continue
+		}
+		key, _, _ = strings.Cut(key, ",")
+		if !ok || key == "" || key == "-" || !field.IsExported() {
+			continue
+		}
+		raw := source(key)
+		if len(raw) == 0 {
+			continue
+		}
+		if err := setBindValues(value.Field(i), raw); nil != err {
+			errorData := ErrorData{Name: key, Path: key, Type: field.Type.String(), Value: strings.Join(raw, ","), Validator: "type", Criteria: field.Type.String()}
+			errorData.Message = ValidationMessage(GetLanguage(c), errorData)
+			return SetErrorBadRequest(message).WithErrorData(errorData).WithCause(err)
+		}
+	}
+
+	return nil
+}
+
+// setBindValues set values into slice field, comma separated values are split, other fields use the last value
+func setBindValues(field reflect.Value, raw []string) error {
+	if field.Kind() != reflect.Slice || field.Type().Elem().Kind() == reflect.Uint8 {
+		return setBindValue(field, raw[len(raw)-1])
+	}
+
+	values := []string{}
+	for _, value := range raw {
+		values = append(values, strings.Split(value, ",")...)
+	}
+	slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+	for i, value := range values {
+		if err := setBindValue(slice.Index(i), strings.TrimSpace(value)); nil != err {
+			return err
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
+// setBindValue set string value into field of basic kinds or encoding.TextUnmarshaler
+func setBindValue(field reflect.Value, raw string) error {
+	if field.Kind() == reflect.Ptr {
+		value := reflect.New(field.Type().Elem())
+		if err := setBindValue(value.Elem(), raw); nil != err {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if nil != err {
+			return err
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if nil != err {
+			return err
+		}
+		field.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if nil != err {
+			return err
+		}
+		field.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if nil != err {
+			return err
+		}
+		field.SetFloat(value)
+	default:
+		return fmt.Errorf("unsupported header field type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package lib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+)
+
+func TestBind(t *testing.T) {
+	type passenger struct {
+		FullName string `json:"full_name" validate:"required"`
+	}
+	type base struct {
+		Note string `json:"note"`
+	}
+	type updateBooking struct {
+		base
+		ID         uuid.UUID   `params:"id" validate:"required"`
+		Notify     bool        `query:"notify"`
+		UserID     *uuid.UUID  `header:"X-User-ID"`
+		Retry      int         `header:"X-Retry"`
+		Code       string      `json:"code" validate:"required"`
+		Passengers []passenger `json:"passengers" validate:"dive"`
+		Extra      map[string]interface{}
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Put("/bookings/:id", func(c *fiber.Ctx) error {
+		payload := updateBooking{}
+		if err := Bind(c, &payload, BindOptions{Strict: true, MaxBodySize: 256, ContentType: fiber.MIMEApplicationJSON}); nil != err {
+			return err
+		}
+		return c.JSON(fiber.Map{
+			"id":      payload.ID,
+			"notify":  payload.Notify,
+			"user_id": payload.UserID,
+			"retry":   payload.Retry,
+			"code":    payload.Code,
+			"note":    payload.Note,
+		})
+	})
+
+	id := uuid.New()
+	userID := uuid.New()
+	send := func(body, contentType string, headers map[string]string) (int, map[string]interface{}) {
+		request := httptest.NewRequest("PUT", "/bookings/"+id.String()+"?notify=true", strings.NewReader(body))
+		request.Header.Set(fiber.HeaderContentType, contentType)
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+		response, err := app.Test(request)
+		utils.AssertEqual(t, nil, err, "sending request")
+		content, _ := io.ReadAll(response.Body)
+		result := map[string]interface{}{}
+		JSONUnmarshal(content, &result)
+		return response.StatusCode, result
+	}
+
+	status, body := send(`{"code":"BK-1","note":"window seat","Extra":{"any":1},"passengers":[{"full_name":"John"}]}`, fiber.MIMEApplicationJSON,
+		map[string]string{"X-User-ID": userID.String(), "X-Retry": "2"})
+	utils.AssertEqual(t, 200, status, "bound")
+	utils.AssertEqual(t, id.String(), body["id"], "path param")
+	utils.AssertEqual(t, true, body["notify"], "query")
+	utils.AssertEqual(t, userID.String(), body["user_id"], "header")
+	utils.AssertEqual(t, float64(2), body["retry"], "numeric header")
+	utils.AssertEqual(t, "window seat", body["note"], "embedded body field")
+
+	status, body = send(`{"code":"BK-1","status":"paid","passengers":[{"full_name":"John","age":30}]}`, fiber.MIMEApplicationJSON, nil)
+	utils.AssertEqual(t, 400, status, "unknown fields")
+	errs := body["error_data"].([]interface{})
+	utils.AssertEqual(t, 2, len(errs), "unknown field entries")
+	utils.AssertEqual(t, "passengers[0].age", errs[0].(map[string]interface{})["path"], "nested unknown field")
+	utils.AssertEqual(t, "status", errs[1].(map[string]interface{})["path"], "unknown field")
+	utils.AssertEqual(t, "unknown_field", errs[1].(map[string]interface{})["validator"], "unknown validator")
+
+	status, body = send(`{"code":10}`, fiber.MIMEApplicationJSON, nil)
+	utils.AssertEqual(t, 400, status, "type error")
+	errs = body["error_data"].([]interface{})
+	utils.AssertEqual(t, "code", errs[0].(map[string]interface{})["path"], "type error path")
+
+	status, body = send(`{"passengers":[{}]}`, fiber.MIMEApplicationJSON, nil)
+	utils.AssertEqual(t, 400, status, "validation error")
+	utils.AssertEqual(t, 2, len(body["error_data"].([]interface{})), "validation error data")
+
+	status, _ = send(`code=BK-1`, fiber.MIMEApplicationForm, nil)
+	utils.AssertEqual(t, 415, status, "content type")
+
+	status, _ = send(`{"code":"`+strings.Repeat("x", 300)+`"}`, fiber.MIMEApplicationJSON, nil)
+	utils.AssertEqual(t, 413, status, "body size")
+
+	status, _ = send(`{"code":"BK-1"}`, fiber.MIMEApplicationJSON, map[string]string{"X-Retry": "twice"})
+	utils.AssertEqual(t, 400, status, "invalid header")
+}
+
+func TestBindTaggedFieldsOnly(t *testing.T) {
+	type payload struct {
+		Notify bool     `query:"notify"`
+		Tags   []string `query:"tag"`
+		Note   string   `json:"note"`
+		Owner  string   `json:"-"`
+		Code   string
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/", func(c *fiber.Ctx) error {
+		data := payload{}
+		if err := Bind(c, &data, BindOptions{Strict: true}); nil != err {
+			return err
+		}
+		return c.JSON(fiber.Map{"notify": data.Notify, "tags": data.Tags, "note": data.Note, "owner": data.Owner, "code": data.Code})
+	})
+
+	request := httptest.NewRequest("POST", "/?notify=true&tag=a,b&tag=c&Note=x&note=x&Owner=attacker&owner=attacker&Code=x", strings.NewReader(`{"note":"body"}`))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	response, err := app.Test(request)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 200, response.StatusCode)
+	content, _ := io.ReadAll(response.Body)
+	body := map[string]interface{}{}
+	JSONUnmarshal(content, &body)
+	utils.AssertEqual(t, true, body["notify"], "tagged query field")
+	utils.AssertEqual(t, []interface{}{"a", "b", "c"}, body["tags"], "tagged query slice")
+	utils.AssertEqual(t, "body", body["note"], "query does not overwrite body field")
+	utils.AssertEqual(t, "", body["owner"], "query does not set json:\"-\" field")
+	utils.AssertEqual(t, "", body["code"], "query does not set untagged field")
+}
+
+func TestBindCompressedBody(t *testing.T) {
+	type payload struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Post("/", func(c *fiber.Ctx) error {
+		data := payload{}
+		if err := Bind(c, &data, BindOptions{MaxBodySize: 256}); nil != err {
+			return err
+		}
+		return c.JSON(fiber.Map{"code": data.Code})
+	})
+	app.Post("/large", func(c *fiber.Ctx) error {
+		data := payload{}
+		return Bind(c, &data, BindOptions{MaxBodySize: 1 << 20})
+	})
+
+	send := func(body string) int {
+		compressed := bytes.Buffer{}
+		writer := gzip.NewWriter(&compressed)
+		writer.Write([]byte(body))
+		writer.Close()
+
+		request := httptest.NewRequest("POST", "/", &compressed)
+		request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		request.Header.Set(fiber.HeaderContentEncoding, fiber.StrGzip)
+		response, err := app.Test(request)
+		utils.AssertEqual(t, nil, err)
+		return response.StatusCode
+	}
+
+	utils.AssertEqual(t, 200, send(`{"code":"BK-1"}`), "compressed body")
+	utils.AssertEqual(t, 413, send(`{"code":"`+strings.Repeat("x", 100000)+`"}`), "decoded body size")
+
+	// decompression bomb smaller than the limit, decoding stops at the limit
+	bomb := bytes.Buffer{}
+	writer := gzip.NewWriter(&bomb)
+	writer.Write([]byte(`{"code":"`))
+	zeros := make([]byte, 1<<20)
+	for i := 0; i < 128; i++ {
+		writer.Write(zeros)
+	}
+	writer.Close()
+	utils.AssertEqual(t, true, bomb.Len() < 1<<20, "compressed bomb size")
+	request := httptest.NewRequest("POST", "/large", &bomb)
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	request.Header.Set(fiber.HeaderContentEncoding, fiber.StrGzip)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	response, err := app.Test(request)
+	runtime.ReadMemStats(&after)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 413, response.StatusCode, "compressed bomb")
+	utils.AssertEqual(t, true, after.TotalAlloc-before.TotalAlloc < 32<<20, "bomb is not expanded")
+
+	compressed := bytes.Buffer{}
+	brWriter := brotli.NewWriter(&compressed)
+	brWriter.Write([]byte(`{"code":"BK-2"}`))
+	brWriter.Close()
+	request = httptest.NewRequest("POST", "/", &compressed)
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	request.Header.Set(fiber.HeaderContentEncoding, fiber.StrBr)
+	response, err = app.Test(request)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 200, response.StatusCode, "brotli body")
+
+	request = httptest.NewRequest("POST", "/", strings.NewReader("not gzip"))
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	request.Header.Set(fiber.HeaderContentEncoding, fiber.StrGzip)
+	response, err = app.Test(request)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 400, response.StatusCode, "invalid encoding")
+}
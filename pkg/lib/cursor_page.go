@@ -0,0 +1,378 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	cursorDirectionNext = "next"
+	cursorDirectionPrev = "prev"
+	defaultCursorKey    = "id"
+)
+
+// ErrInvalidCursor cursor is malformed, tampered or created for another sort order, filters or search
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorFallbackSecret used to sign cursors when CURSOR_SECRET is not configured,
+// cursors signed with this secret are only valid within the current process
+var cursorFallbackSecret []byte = func() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); nil != err {
+		panic("cursor secret: " + err.Error())
+	}
+	return secret
+}()
+
+// CursorPage cursor (keyset) pagination model
+type CursorPage struct {
+	Items      interface{} `json:"items" swaggertype:"object"`
+	Size       int64       `json:"size" example:"10"`                           // size per page, default `10`
+	NextCursor string      `json:"next_cursor,omitempty" example:"eyJkIjoi..."` // cursor of the next page
+	PrevCursor string      `json:"prev_cursor,omitempty" example:"eyJkIjoi..."` // cursor of the previous page
+	HasNext    bool        `json:"has_next" example:"true"`                     // indicate next page exists
+	HasPrev    bool        `json:"has_prev" example:"false"`                    // indicate previous page exists
+	Visible    int64       `json:"visible" example:"10"`                        // current length
+}
+
+// CursorQuery cursor pagination query parameters
+type CursorQuery struct {
+	ListQuery
+	Cursor string `json:"cursor" query:"cursor"` // cursor from next_cursor or prev_cursor
+	Key    string `json:"-" query:"-"`           // unique sort key used as tie breaker, default `id`
+}
+
+// cursorToken signed cursor content
+type cursorToken struct {
+	Direction string        `json:"d"`
+	Sort      string        `json:"s"`
+	Filter    string        `json:"f"` // hash of filters and search, see cursorFilterHash
+	Values    []interface{} `json:"v"`
+}
+
+// sortField single sort field, ex: -created_at
+type sortField struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+// GetCursorQuery get cursor pagination query parameters from http request
+func GetCursorQuery(c *fiber.Ctx) CursorQuery {
+	return CursorQuery{
+		ListQuery: GetListQuery(c),
+		Cursor:    c.Query("cursor"),
+	}
+}
+
+// CursorPaginate find items using keyset pagination, the sort fields of the query
+// and the unique key are used as the keyset so no OFFSET scan is needed
+//
+//	=> Example
+//	bookings := []model.Booking{}
+//	page, err := lib.CursorPaginate(db, &model.Booking{}, &bookings, lib.GetCursorQuery(c))
+//	if nil != err {
+//		return lib.ErrorBadRequest(c, err)
+//	}
+//	return lib.OK(c, page)
+//
+//gocyclo:ignore
+func CursorPaginate(db *gorm.DB, model interface{}, items interface{}, query CursorQuery, schema ...QuerySchema) (CursorPage, error) {
+	query.normalize()
+	page := CursorPage{Items: items, Size: query.Size}
+
+	fields, err := cursorSortFields(query, DialectOf(db), schema...)
+	if nil != err {
+		return page, err
+	}
+	sortKey := sortFieldsString(fields)
+	filterHash := cursorFilterHash(query.ListQuery)
+
+	token := cursorToken{Direction: cursorDirectionNext}
+	if query.Cursor != "" {
+		if token, err = decodeCursor(query.Cursor); nil != err {
+			return page, err
+		}
+		if token.Sort != sortKey || token.Filter != filterHash || len(token.Values) != len(fields) {
+			return page, ErrInvalidCursor
+		}
+	}
+
+	tx, err := applyListFilters(db.Model(model), query.ListQuery, schema...)
+	if nil != err {
+		return page, err
+	}
+
+	backward := token.Direction == cursorDirectionPrev
+	if query.Cursor != "" {
+		where, params := keysetCondition(fields, token.Values, backward)
+		tx = tx.Where(where, params...)
+	}
+
+	orders := []string{}
+	for _, f := range fields {
+		if f.Desc != backward {
+			orders = append(orders, f.Column+" DESC")
+		} else {
+			orders = append(orders, f.Column+" ASC")
+		}
+	}
+
+	if err := tx.Order(strings.Join(orders, ",")).Limit(int(query.Size + 1)).Find(items).Error; nil != err {
+		return page, err
+	}
+
+	rows := reflect.ValueOf(items)
+	for rows.Kind() == reflect.Ptr {
+		rows = rows.Elem()
+	}
+	if rows.Kind() != reflect.Slice {
+		return page, fmt.Errorf("cursor pagination items must be a pointer to slice, got %T", items)
+	}
+
+	hasMore := int64(rows.Len()) > query.Size
+	if hasMore {
+		rows.Set(rows.Slice(0, int(query.Size)))
+	}
+	if backward {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	page.Visible = int64(rows.Len())
+	page.HasNext = (!backward && hasMore) || (backward && query.Cursor != "")
+	page.HasPrev = (backward && hasMore) || (!backward && query.Cursor != "")
+
+	if rows.Len() == 0 {
+		return page, nil
+	}
+
+	itemSchema := &gorm.Statement{DB: db}
+	if err := itemSchema.Parse(model); nil != err {
+		return page, err
+	}
+
+	if page.HasNext {
+		values, err := cursorValues(itemSchema, rows.Index(rows.Len()-1), fields)
+		if nil != err {
+			return page, err
+		}
+		page.NextCursor = encodeCursor(cursorToken{Direction: cursorDirectionNext, Sort: sortKey, Filter: filterHash, Values: values})
+	}
+	if page.HasPrev {
+		values, err := cursorValues(itemSchema, rows.Index(0), fields)
+		if nil != err {
+			return page, err
+		}
+		page.PrevCursor = encodeCursor(cursorToken{Direction: cursorDirectionPrev, Sort: sortKey, Filter: filterHash, Values: values})
+	}
+
+	return page, nil
+}
+
+// cursorSortFields validated sort fields of the query with the unique key as the last field,
+// nullable schema fields are rejected because row value comparison never matches NULL
+func cursorSortFields(query CursorQuery, dialect FilterDialect, schema ...QuerySchema) ([]sortField, error) {
+	key := query.Key
+	if key == "" {
+		key = defaultCursorKey
+	}
+
+	var items SortItems
+	var err error
+	if len(schema) > 0 {
+		items, err = schema[0].ParseSort(query.Sort)
+	} else {
+		items, err = ParseSort(query.Sort)
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	fields := []sortField{}
+	hasKey := false
+	for _, item := range items {
+		if item.Nulls != "" {
+			return nil, fmt.Errorf("sort field %s: nulls ordering is not supported by cursor pagination", item.Field)
+		}
+		if len(schema) > 0 && schema[0].Fields[item.Field].Nullable {
+			return nil, fmt.Errorf("sort field %s: nullable fields are not supported by cursor pagination", item.Field)
+		}
+		column := item.Column
+		if column == "" {
+			column = sortColumn(item.Field, dialect)
+		}
+		hasKey = hasKey || item.Field == key
+		fields = append(fields, sortField{Field: item.Field, Column: column, Desc: item.Desc})
+	}
+	if !hasKey {
+		column := sortColumn(key, dialect)
+		if len(schema) > 0 {
+			if c, ok := schema[0].Column(key); ok {
+				column = c
+			}
+		}
+		fields = append(fields, sortField{Field: key, Column: column})
+	}
+
+	return fields, nil
+}
+
+// cursorFilterHash hash of filters and search signed into the cursor, so a cursor is only valid for the same list query
+func cursorFilterHash(query ListQuery) string {
+	sum := sha256.Sum256([]byte(query.Filters + "\x00" + query.Search + "\x00" + query.Columns))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func sortFieldsString(fields []sortField) string {
+	sorts := []string{}
+	for _, f := range fields {
+		if f.Desc {
+			sorts = append(sorts, "-"+f.Field)
+		} else {
+			sorts = append(sorts, f.Field)
+		}
+	}
+	return strings.Join(sorts, ",")
+}
+
+// keysetCondition where clause of rows after (or before) the keyset values
+//
+//	(a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND c > ?)
+func keysetCondition(fields []sortField, values []interface{}, backward bool) (string, []interface{}) {
+	conditions := []string{}
+	params := []interface{}{}
+	for i, f := range fields {
+		parts := []string{}
+		for j := 0; j < i; j++ {
+			parts = append(parts, fields[j].Column+" = ?")
+			params = append(params, values[j])
+		}
+		operator := ">"
+		if f.Desc != backward {
+			operator = "<"
+		}
+		parts = append(parts, f.Column+" "+operator+" ?")
+		params = append(params, values[i])
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", params
+}
+
+// cursorValues get sort key values of an item, item can be a struct or a map,
+// null values are rejected since the next page could not be found after them
+func cursorValues(stmt *gorm.Statement, item reflect.Value, fields []sortField) ([]interface{}, error) {
+	for item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface {
+		item = item.Elem()
+	}
+
+	values := []interface{}{}
+	for _, f := range fields {
+		switch item.Kind() {
+		case reflect.Map:
+			value := item.MapIndex(reflect.ValueOf(f.Field))
+			if !value.IsValid() {
+				// relation field of nested map, ex: agent__name
+				value = lookupFieldPath(item, f.Field)
+			}
+			if !value.IsValid() {
+				return values, fmt.Errorf("cursor key %s is not found on item", f.Field)
+			}
+			values = append(values, value.Interface())
+		case reflect.Struct:
+			field := stmt.Schema.LookUpField(f.Field)
+			if nil == field && !strings.Contains(f.Field, "__") {
+				columns := strings.Split(f.Column, ".")
+				field = stmt.Schema.LookUpField(strings.Trim(columns[len(columns)-1], "\"`"))
+			}
+			if nil != field {
+				value, _ := field.ValueOf(item)
+				values = append(values, value)
+				break
+			}
+			// relation field resolved like FilterSlice, ex: agent__name -> Agent.Name
+			if !knownField(item.Type(), f.Field) {
+				return values, fmt.Errorf("cursor key %s is not found on item", f.Field)
+			}
+			values = append(values, lookupFieldValue(item, f.Field))
+		default:
+			return values, fmt.Errorf("cursor item must be a struct or map, got %s", item.Kind())
+		}
+		if nil == normalizeValue(values[len(values)-1]) {
+			return values, fmt.Errorf("cursor key %s is null, nullable fields are not supported by cursor pagination", f.Field)
+		}
+	}
+
+	return values, nil
+}
+
+// encodeCursor sign cursor content with CURSOR_SECRET
+func encodeCursor(token cursorToken) string {
+	payload, _ := JSONMarshal(token)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(cursorSignature(encoded))
+}
+
+// decodeCursor verify and decode cursor
+func decodeCursor(cursor string) (cursorToken, error) {
+	token := cursorToken{}
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 {
+		return token, ErrInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if nil != err || !hmac.Equal(signature, cursorSignature(parts[0])) {
+		return token, ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if nil != err {
+		return token, ErrInvalidCursor
+	}
+	// decode numbers as json.Number to keep int64 keys precision
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	if err := decoder.Decode(&token); nil != err {
+		return token, ErrInvalidCursor
+	}
+	for i, value := range token.Values {
+		if number, ok := value.(json.Number); ok {
+			if n, err := number.Int64(); nil == err {
+				token.Values[i] = n
+			} else if f, err := number.Float64(); nil == err {
+				token.Values[i] = f
+			}
+		}
+	}
+	if token.Direction != cursorDirectionNext && token.Direction != cursorDirectionPrev {
+		return token, ErrInvalidCursor
+	}
+
+	return token, nil
+}
+
+func cursorSignature(payload string) []byte {
+	secret := []byte(viper.GetString("CURSOR_SECRET"))
+	if len(secret) == 0 {
+		secret = cursorFallbackSecret
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,174 @@
+package lib
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"gorm.io/gorm"
+)
+
+type cursorSample struct {
+	ID     int64
+	Amount float64
+}
+
+// cursorRowsDB dry run database which returns the given rows for every query
+func cursorRowsDB(t *testing.T, rows *[]cursorSample) (*gorm.DB, *sqlRecorder) {
+	db, recorder := dryRunDB(t)
+	db.Callback().Query().After("gorm:query").Register("test:rows", func(tx *gorm.DB) {
+		reflect.ValueOf(tx.Statement.Dest).Elem().Set(reflect.ValueOf(append([]cursorSample{}, (*rows)...)))
+	})
+	return db, recorder
+}
+
+func TestCursorPaginate(t *testing.T) {
+	rows := []cursorSample{{ID: 1, Amount: 30}, {ID: 2, Amount: 20}, {ID: 3, Amount: 10}}
+	db, recorder := cursorRowsDB(t, &rows)
+
+	// first page
+	items := []cursorSample{}
+	query := CursorQuery{ListQuery: ListQuery{Size: 2, Sort: "-amount"}}
+	page, err := CursorPaginate(db, &cursorSample{}, &items, query)
+	utils.AssertEqual(t, nil, err, "first page")
+	utils.AssertEqual(t, "SELECT * FROM `cursor_samples` ORDER BY amount DESC,id ASC LIMIT 3", recorder.queries[0], "first page query")
+	utils.AssertEqual(t, int64(2), page.Visible, "first page visible")
+	utils.AssertEqual(t, 2, len(items), "first page items")
+	utils.AssertEqual(t, true, page.HasNext, "first page has next")
+	utils.AssertEqual(t, false, page.HasPrev, "first page has prev")
+	utils.AssertEqual(t, "", page.PrevCursor, "first page prev cursor")
+
+	// next page
+	rows = []cursorSample{{ID: 3, Amount: 10}}
+	recorder.queries = []string{}
+	query.Cursor = page.NextCursor
+	page, err = CursorPaginate(db, &cursorSample{}, &items, query)
+	utils.AssertEqual(t, nil, err, "next page")
+	utils.AssertEqual(t, "SELECT * FROM `cursor_samples` WHERE ((amount < 20) OR (amount = 20 AND id > 2)) ORDER BY amount DESC,id ASC LIMIT 3", recorder.queries[0], "next page query")
+	utils.AssertEqual(t, false, page.HasNext, "next page has next")
+	utils.AssertEqual(t, true, page.HasPrev, "next page has prev")
+
+	// previous page, rows are returned in reversed order
+	rows = []cursorSample{{ID: 2, Amount: 20}, {ID: 1, Amount: 30}, {ID: 0, Amount: 40}}
+	recorder.queries = []string{}
+	query.Cursor = page.PrevCursor
+	page, err = CursorPaginate(db, &cursorSample{}, &items, query)
+	utils.AssertEqual(t, nil, err, "prev page")
+	utils.AssertEqual(t, "SELECT * FROM `cursor_samples` WHERE ((amount > 10) OR (amount = 10 AND id < 3)) ORDER BY amount ASC,id DESC LIMIT 3", recorder.queries[0], "prev page query")
+	utils.AssertEqual(t, []cursorSample{{ID: 1, Amount: 30}, {ID: 2, Amount: 20}}, items, "prev page items")
+	utils.AssertEqual(t, true, page.HasNext, "prev page has next")
+	utils.AssertEqual(t, true, page.HasPrev, "prev page has prev")
+}
+
+func TestCursorPaginateInvalidCursor(t *testing.T) {
+	rows := []cursorSample{{ID: 1, Amount: 30}, {ID: 2, Amount: 20}}
+	db, _ := cursorRowsDB(t, &rows)
+
+	items := []cursorSample{}
+	page, err := CursorPaginate(db, &cursorSample{}, &items, CursorQuery{ListQuery: ListQuery{Size: 1, Sort: "-amount"}})
+	utils.AssertEqual(t, nil, err, "first page")
+
+	// tampered payload
+	parts := strings.Split(page.NextCursor, ".")
+	token, _ := decodeCursor(page.NextCursor)
+	token.Values = []interface{}{0, 0}
+	tampered := strings.Split(encodeCursor(token), ".")[0] + "." + parts[1]
+	_, err = CursorPaginate(db, &cursorSample{}, &items, CursorQuery{ListQuery: ListQuery{Size: 1, Sort: "-amount"}, Cursor: tampered})
+	utils.AssertEqual(t, ErrInvalidCursor, err, "tampered cursor")
+
+	// cursor of another sort order
+	_, err = CursorPaginate(db, &cursorSample{}, &items, CursorQuery{ListQuery: ListQuery{Size: 1, Sort: "amount"}, Cursor: page.NextCursor})
+	utils.AssertEqual(t, ErrInvalidCursor, err, "another sort order")
+
+	for _, cursor := range []string{"abc", "abc.def", "." + parts[1]} {
+		_, err = decodeCursor(cursor)
+		utils.AssertEqual(t, ErrInvalidCursor, err, cursor)
+	}
+}
+
+func TestKeysetCondition(t *testing.T) {
+	fields := []sortField{{Column: "created_at", Desc: true}, {Column: "id"}}
+	where, params := keysetCondition(fields, []interface{}{"2023-01-01", 10}, false)
+	utils.AssertEqual(t, "((created_at < ?) OR (created_at = ? AND id > ?))", where, "forward keyset")
+	utils.AssertEqual(t, []interface{}{"2023-01-01", "2023-01-01", 10}, params, "forward keyset params")
+
+	where, _ = keysetCondition(fields, []interface{}{"2023-01-01", 10}, true)
+	utils.AssertEqual(t, "((created_at > ?) OR (created_at = ? AND id < ?))", where, "backward keyset")
+
+	// map items
+	values, err := cursorValues(nil, reflect.ValueOf(map[string]interface{}{"created_at": "2023-01-01", "id": 10}), []sortField{{Field: "created_at"}, {Field: "id"}})
+	utils.AssertEqual(t, nil, err, "map values")
+	utils.AssertEqual(t, []interface{}{"2023-01-01", 10}, values, "map values")
+
+	_, err = cursorValues(nil, reflect.ValueOf(map[string]interface{}{}), []sortField{{Field: "id"}})
+	utils.AssertEqual(t, true, nil != err, "missing map key")
+}
+
+func TestCursorSortFields(t *testing.T) {
+	fields, err := cursorSortFields(CursorQuery{ListQuery: ListQuery{Sort: "-amount"}, Key: "booking_code"}, DialectPostgres)
+	utils.AssertEqual(t, nil, err, "sort fields")
+	utils.AssertEqual(t, []sortField{{Field: "amount", Column: `"amount"`, Desc: true}, {Field: "booking_code", Column: `"booking_code"`}}, fields, "quoted key column")
+
+	schema := QuerySchema{Fields: map[string]QueryField{
+		"amount":  {Sortable: true},
+		"paid_at": {Sortable: true, Nullable: true},
+	}}
+	_, err = cursorSortFields(CursorQuery{ListQuery: ListQuery{Sort: "paid_at"}}, DialectPostgres, schema)
+	utils.AssertEqual(t, true, nil != err && strings.Contains(err.Error(), "nullable"), "nullable schema field")
+
+	_, err = cursorValues(nil, reflect.ValueOf(map[string]interface{}{"paid_at": nil, "id": 1}), []sortField{{Field: "paid_at"}, {Field: "id"}})
+	utils.AssertEqual(t, true, nil != err && strings.Contains(err.Error(), "null"), "null cursor value")
+}
+
+func TestCursorRelationValues(t *testing.T) {
+	type cursorAgent struct {
+		ID   int64
+		Name string
+	}
+	type cursorBooking struct {
+		ID      int64
+		AgentID int64
+		Agent   *cursorAgent
+	}
+
+	db, _ := dryRunDB(t)
+	stmt := &gorm.Statement{DB: db}
+	utils.AssertEqual(t, nil, stmt.Parse(&cursorBooking{}), "parse model")
+
+	fields, err := cursorSortFields(CursorQuery{ListQuery: ListQuery{Sort: "-agent__name"}}, DialectPostgres)
+	utils.AssertEqual(t, nil, err, "relation sort fields")
+	utils.AssertEqual(t, `"Agent__name"`, fields[0].Column, "relation column")
+
+	values, err := cursorValues(stmt, reflect.ValueOf(cursorBooking{ID: 7, Agent: &cursorAgent{Name: "John"}}), fields)
+	utils.AssertEqual(t, nil, err, "relation values")
+	utils.AssertEqual(t, []interface{}{"John", int64(7)}, values, "relation values")
+
+	_, err = cursorValues(stmt, reflect.ValueOf(cursorBooking{ID: 8}), fields)
+	utils.AssertEqual(t, true, nil != err && strings.Contains(err.Error(), "null"), "missing relation")
+
+	_, err = cursorValues(stmt, reflect.ValueOf(cursorBooking{ID: 8}), []sortField{{Field: "agent__email"}})
+	utils.AssertEqual(t, true, nil != err && strings.Contains(err.Error(), "not found"), "unknown relation field")
+
+	values, err = cursorValues(nil, reflect.ValueOf(map[string]interface{}{"agent": map[string]interface{}{"name": "Jane"}, "id": 1}), fields)
+	utils.AssertEqual(t, nil, err, "nested map values")
+	utils.AssertEqual(t, []interface{}{"Jane", 1}, values, "nested map values")
+}
+
+func TestCursorFilterReplay(t *testing.T) {
+	rows := []cursorSample{{ID: 1, Amount: 30}, {ID: 2, Amount: 20}}
+	db, _ := cursorRowsDB(t, &rows)
+
+	items := []cursorSample{}
+	query := CursorQuery{ListQuery: ListQuery{Size: 1, Sort: "-amount", Filters: `["amount",">",10]`}}
+	page, err := CursorPaginate(db, &cursorSample{}, &items, query)
+	utils.AssertEqual(t, nil, err, "first page")
+
+	query.Cursor = page.NextCursor
+	_, err = CursorPaginate(db, &cursorSample{}, &items, query)
+	utils.AssertEqual(t, nil, err, "same filters")
+
+	query.Filters = `["amount",">",0]`
+	_, err = CursorPaginate(db, &cursorSample{}, &items, query)
+	utils.AssertEqual(t, ErrInvalidCursor, err, "cursor replayed with other filters")
+}
@@ -2,8 +2,11 @@ package lib
 
 import (
 	"fmt"
+	"log"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/iancoleman/strcase"
 )
@@ -16,6 +19,16 @@ type FilterItem struct {
 	ValueType string
 }
 
+// extended filter operators
+const (
+	FilterStartsWith  = "STARTS WITH"  // ["name","STARTS WITH","jo"]
+	FilterEndsWith    = "ENDS WITH"    // ["email","ENDS WITH","@mail.com"]
+	FilterContains    = "@>"           // ["tags","@>",["a","b"]] or ["meta","@>",{"key":"value"}], postgres array / jsonb containment
+	FilterIsEmpty     = "IS EMPTY"     // ["note","IS EMPTY",null], null or empty string
+	FilterIsNotEmpty  = "IS NOT EMPTY" // ["note","IS NOT EMPTY",null]
+	FilterDateBetween = "DATE BETWEEN" // ["created_at","DATE BETWEEN",["2023-01-01","2023-01-31","+07:00"]], whole days in the optional timezone
+)
+
 // QueryFilter struct
 type QueryFilter struct {
 	Item FilterItem
@@ -25,68 +38,21 @@ type QueryFilter struct {
 // CustomFilters func
 // => Example
 // -> QueryFilters      := [["id","=","6"],["AND"],["status_transaction","=","waiting"],["AND"],["business_id","=","10"]]
+// -> QueryFilters      := [["status","IN",["x","y"]],["AND"],[["agent_id","=","A"],["OR"],["corporate_id","=","B"]]]
+// -> QueryFilters      := [["id","=","6"],["AND"],["NOT",["status","=","cancelled"]]]
+// -> QueryFilters      := [["name","STARTS WITH","jo"],["AND"],["created_at","DATE BETWEEN",["2023-01-01","2023-01-31","Asia/Jakarta"]]]
+// -> QueryFilters      := [["tags","@>",["vip"]],["AND"],["note","IS NOT EMPTY",null],["AND"],["amount","BETWEEN",[10,20]]]
 // -> QuerySearch       := "value"
 // -> columnFilters    := ["trx_id","id"]
 //
-//gocyclo:ignore
+// malformed filters never match any row instead of being dropped,
+// use ParseCustomFilters to get the parse errors
 func CustomFilters(QueryFilters, QuerySearch, columnFilter string) (string, []interface{}, string, []interface{}) {
-	queryFilters := []string{}
-	whereFilters := []interface{}{}
-	ResultFilters := ""
-
-	querySearch := []string{}
-	whereSearch := []interface{}{}
-	ResultSearch := ""
-
-	// search_by _column
-	if columnFilter != "" {
-		columnFilters := StringToJson(columnFilter)
-		if nil != columnFilters {
-			if CountLengthIface(columnFilters) > 0 {
-				if QuerySearch != "" {
-					switch reflect.TypeOf(columnFilters).Kind() {
-					case reflect.Slice:
-						s := reflect.ValueOf(columnFilters)
-						for i := 0; i < s.Len(); i++ {
-							value := s.Index(i).Interface().(string)
-							querySearch = append(querySearch, ""+value+" LIKE ?")
-							whereSearch = append(whereSearch, "%"+QuerySearch+"%")
-						}
-					}
-					if len(querySearch) > 0 {
-						ResultSearch = "" + strings.Join(querySearch, " OR ") + ""
-					}
-				}
-			}
-		}
-	}
-
-	// filters_by_field
-	if QueryFilters != "" {
-		filters := CreateFilter(QueryFilters)
-		if len(filters) == 1 && filters[0].Type == "single" {
-			CreateWhereCause(filters[0], &queryFilters, &whereFilters)
-		} else if len(filters) >= 1 {
-			for b, filter := range filters {
-				if filter.Type == "operator" {
-					queryFilters = append(queryFilters, filter.Item.Operator)
-					continue
-				} else if filter.Type == "multiple" {
-					if b > 0 && filters[b-1].Type != "operator" {
-						queryFilters = append(queryFilters, " OR ")
-					}
-					CreateWhereCause(filter, &queryFilters, &whereFilters)
-				}
-			}
-		}
-
-		if len(queryFilters) > 0 {
-			ResultFilters = "" + strings.Join(queryFilters, " ") + ""
-		}
+	ResultFilters, whereFilters, ResultSearch, whereSearch, err := ParseCustomFilters(QueryFilters, QuerySearch, columnFilter)
+	if nil != err {
+		log.Printf("CustomFilters, %s", err.Error())
+		return "1 = 0", []interface{}{}, "", []interface{}{}
 	}
-
-	ResultFilters = strings.ReplaceAll(ResultFilters, "\"", "")
-
 	return ResultFilters, whereFilters, ResultSearch, whereSearch
 }
 
@@ -136,18 +102,18 @@ func CreateFilter(jsonParams string) []QueryFilter {
 				}
 				for i, a := range item {
 					if len(item) == 1 {
-						filter.Item.Operator = a.(string)
+						filter.Item.Operator, _ = a.(string)
 						filter.Type = "operator"
 						continue
 					}
 					if i == 0 {
-						filter.Item.Field = a.(string)
+						filter.Item.Field, _ = a.(string)
 					} else if i == 1 {
 						if len(item) == 2 {
 							filter.Item.Operator = "="
 							SetFilterValue(&filter.Item, a)
 						} else {
-							filter.Item.Operator = a.(string)
+							filter.Item.Operator, _ = a.(string)
 						}
 					} else if i == 2 {
 						SetFilterValue(&filter.Item, a)
@@ -192,6 +158,7 @@ func SetFilterValue(item *FilterItem, a interface{}) {
 	boolValue, isBool := a.(bool)
 	floatValue, isFloat := a.(float64)
 	arrayValue, isArray := a.([]interface{})
+	objectValue, isObject := a.(map[string]interface{})
 	if isString {
 		item.Value = stringValue
 		item.ValueType = "string"
@@ -204,6 +171,9 @@ func SetFilterValue(item *FilterItem, a interface{}) {
 	} else if isArray {
 		item.Value = arrayValue
 		item.ValueType = "array"
+	} else if isObject {
+		item.Value = objectValue
+		item.ValueType = "object"
 	}
 }
 
@@ -211,6 +181,17 @@ func SetFilterValue(item *FilterItem, a interface{}) {
 //
 //gocyclo:ignore
 func CreateWhereCause(filter QueryFilter, queryFilters *[]string, whereParams *[]interface{}) {
+	if isExtendedOperator(filter.Item.Operator) {
+		cause, params, err := extendedWhereCause(filter.Item, filter.Item.Field, "")
+		if nil != err {
+			// invalid values never match instead of widening the result
+			cause, params = "1 = 0", nil
+		}
+		*queryFilters = append(*queryFilters, cause)
+		*whereParams = append(*whereParams, params...)
+		return
+	}
+
 	if (filter.Item.Operator == "IS" || filter.Item.Operator == "IS NOT") && filter.Item.Value == nil {
 		*queryFilters = append(*queryFilters, fmt.Sprintf("(%s %s NULL)",
 			filter.Item.Field,
@@ -219,11 +200,14 @@ func CreateWhereCause(filter QueryFilter, queryFilters *[]string, whereParams *[
 		return
 	}
 
-	if filter.Item.Operator == "LIKE" || filter.Item.Operator == "NOT LIKE" {
+	if filter.Item.Operator == "LIKE" || filter.Item.Operator == "NOT LIKE" || filter.Item.Operator == "ILIKE" || filter.Item.Operator == "NOT ILIKE" {
 		cause := fmt.Sprintf("%s %s ?",
 			filter.Item.Field,
 			filter.Item.Operator,
 		)
+		if strings.HasSuffix(filter.Item.Operator, "ILIKE") {
+			cause = lowerLike(filter.Item.Field, filter.Item.Operator)
+		}
 		*queryFilters = append(*queryFilters, cause)
 		value := ""
 		switch filter.Item.ValueType {
@@ -249,10 +233,12 @@ func CreateWhereCause(filter QueryFilter, queryFilters *[]string, whereParams *[
 			if ok {
 				values := []interface{}{}
 				for _, val := range value {
-					v, o := val.(string)
-					if o {
+					switch v := val.(type) {
+					case string:
 						values = append(values, strings.ToLower(v))
-					} else {
+					case float64:
+						values = append(values, strconv.FormatFloat(v, 'f', -1, 64))
+					default:
 						values = append(values, fmt.Sprintf("%v", v))
 					}
 				}
@@ -270,9 +256,11 @@ func CreateWhereCause(filter QueryFilter, queryFilters *[]string, whereParams *[
 			if ok {
 				values := []interface{}{}
 				for _, val := range value {
-					v, o := val.(string)
-					if o {
+					switch v := val.(type) {
+					case string:
 						values = append(values, strings.ToLower(v))
+					case float64:
+						values = append(values, v)
 					}
 				}
 
@@ -291,6 +279,132 @@ func CreateWhereCause(filter QueryFilter, queryFilters *[]string, whereParams *[
 	}
 }
 
+// lowerLike case-insensitive ILIKE / NOT ILIKE condition which is supported by every database
+func lowerLike(column, operator string) string {
+	return fmt.Sprintf("LOWER(%s) %s LOWER(?)", column, strings.Replace(operator, "ILIKE", "LIKE", 1))
+}
+
+// isExtendedOperator check whether operator is compiled by extendedWhereCause
+func isExtendedOperator(operator string) bool {
+	switch operator {
+	case FilterStartsWith, FilterEndsWith, FilterContains, FilterIsEmpty, FilterIsNotEmpty, FilterDateBetween:
+		return true
+	}
+	return false
+}
+
+// extendedWhereCause where clause and bind parameters of extended operators
+//
+//gocyclo:ignore
+func extendedWhereCause(item FilterItem, column string, dialect FilterDialect) (string, []interface{}, error) {
+	switch item.Operator {
+	case FilterStartsWith, FilterEndsWith:
+		// the value is matched literally, % and _ are escaped like SearchModeTerms
+		pattern := EscapeLike(fmt.Sprintf("%v", item.Value)) + "%"
+		if item.Operator == FilterEndsWith {
+			pattern = "%" + EscapeLike(fmt.Sprintf("%v", item.Value))
+		}
+		condition := column + " LIKE ?"
+		if dialect != "" {
+			condition = dialect.Like(column, false)
+		}
+		return condition + " ESCAPE '" + searchEscape + "'", []interface{}{pattern}, nil
+
+	case FilterIsEmpty:
+		return fmt.Sprintf("(%s IS NULL OR %s = ?)", column, column), []interface{}{""}, nil
+
+	case FilterIsNotEmpty:
+		return fmt.Sprintf("(%s IS NOT NULL AND %s <> ?)", column, column), []interface{}{""}, nil
+
+	case FilterContains:
+		_, isObject := item.Value.(map[string]interface{})
+		values, isArray := item.Value.([]interface{})
+		if !isObject && (!isArray || len(values) == 0) {
+			return "", nil, fmt.Errorf("operator @> on %s requires a non-empty array or an object", item.Field)
+		}
+		switch dialect {
+		case "", DialectPostgres:
+		case DialectMySQL:
+			document, _ := JSONMarshal(item.Value)
+			return fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), []interface{}{string(document)}, nil
+		default:
+			return "", nil, fmt.Errorf("operator @> is not supported by %s", dialect)
+		}
+		if isObject || item.ValueType == QueryFieldJSON {
+			document, _ := JSONMarshal(item.Value)
+			return column + " @> ?::jsonb", []interface{}{string(document)}, nil
+		}
+		return column + " @> ARRAY[" + strings.TrimSuffix(strings.Repeat("?,", len(values)), ",") + "]", values, nil
+
+	case FilterDateBetween:
+		values, _ := item.Value.([]interface{})
+		from, to, err := filterDateRange(values)
+		if nil != err {
+			return "", nil, fmt.Errorf("operator DATE BETWEEN on %s: %s", item.Field, err.Error())
+		}
+		if item.ValueType == QueryFieldDate {
+			return column + " BETWEEN ? AND ?", []interface{}{from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02")}, nil
+		}
+		return fmt.Sprintf("(%s >= ? AND %s < ?)", column, column), []interface{}{from.UTC(), to.UTC()}, nil
+	}
+
+	return "", nil, fmt.Errorf("unsupported operator %s", item.Operator)
+}
+
+// filterDateRange parse DATE BETWEEN values [from, to, timezone] into the start of the
+// from day and the start of the day after the to day, timezone is an IANA name or an offset like +07:00
+func filterDateRange(values []interface{}) (time.Time, time.Time, error) {
+	var from, to time.Time
+	if len(values) != 2 && len(values) != 3 {
+		return from, to, fmt.Errorf("requires an array of 2 dates and an optional timezone")
+	}
+
+	dates := []string{}
+	for _, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return from, to, fmt.Errorf("value %v is not a string", value)
+		}
+		dates = append(dates, strings.TrimSpace(s))
+	}
+
+	location := time.UTC
+	if len(dates) == 3 {
+		loc, err := filterLocation(dates[2])
+		if nil != err {
+			return from, to, err
+		}
+		location = loc
+	}
+
+	from, err := time.ParseInLocation("2006-01-02", dates[0], location)
+	if nil != err {
+		return from, to, fmt.Errorf("value %s is not a valid date", dates[0])
+	}
+	to, err = time.ParseInLocation("2006-01-02", dates[1], location)
+	if nil != err {
+		return from, to, fmt.Errorf("value %s is not a valid date", dates[1])
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("date %s is before %s", dates[1], dates[0])
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// filterLocation load timezone by IANA name or UTC offset
+func filterLocation(timezone string) (*time.Location, error) {
+	if offset, err := time.Parse("-07:00", timezone); nil == err {
+		_, seconds := offset.Zone()
+		return time.FixedZone(timezone, seconds), nil
+	}
+	location, err := time.LoadLocation(timezone)
+	if nil != err {
+		return nil, fmt.Errorf("unknown timezone %s", timezone)
+	}
+	return location, nil
+}
+
 // StringToJson func
 func StringToJson(value string) interface{} {
 	var output interface{}
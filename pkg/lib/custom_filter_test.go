@@ -3,6 +3,9 @@ package lib
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2/utils"
 )
 
 func TestCustomFilters(t *testing.T) {
@@ -73,3 +76,66 @@ func TestCustomFilters(t *testing.T) {
 	// filter negative case
 	CreateFilter("unexpected json format")
 }
+
+func TestCustomFiltersExtendedOperators(t *testing.T) {
+	cases := []struct {
+		filter string
+		query  string
+		params []interface{}
+	}{
+		{`["name","STARTS WITH","jo"]`, "name LIKE ? ESCAPE '!'", []interface{}{"jo%"}},
+		{`["email","ENDS WITH","@mail.com"]`, "email LIKE ? ESCAPE '!'", []interface{}{"%@mail.com"}},
+		{`["code","STARTS WITH","jo_50%!"]`, "code LIKE ? ESCAPE '!'", []interface{}{"jo!_50!%!!%"}},
+		{`["note","IS EMPTY",null]`, "(note IS NULL OR note = ?)", []interface{}{""}},
+		{`["note","IS NOT EMPTY",null]`, "(note IS NOT NULL AND note <> ?)", []interface{}{""}},
+		{`["tags","@>",["a","b"]]`, "tags @> ARRAY[?,?]", []interface{}{"a", "b"}},
+		{`["meta","@>",{"vip":true}]`, "meta @> ?::jsonb", []interface{}{`{"vip":true}`}},
+		{`["amount","BETWEEN",[10,20.5]]`, "amount BETWEEN ? AND ?", []interface{}{float64(10), 20.5}},
+		{`["created_at","DATE BETWEEN",["2023-01-01","2023-01-31","+07:00"]]`, "(created_at >= ? AND created_at < ?)", []interface{}{
+			time.Date(2022, 12, 31, 17, 0, 0, 0, time.UTC),
+			time.Date(2023, 1, 31, 17, 0, 0, 0, time.UTC),
+		}},
+	}
+	for _, c := range cases {
+		query, params, _, _, err := ParseCustomFilters(c.filter, "", "")
+		utils.AssertEqual(t, nil, err, c.filter)
+		utils.AssertEqual(t, c.query, query, c.filter)
+		utils.AssertEqual(t, c.params, params, c.filter)
+	}
+
+	invalid := []string{
+		`["name","STARTS WITH",true]`,
+		`["note","IS EMPTY","x"]`,
+		`["tags","@>",[]]`,
+		`["tags","=",{"a":1}]`,
+		`["amount","BETWEEN",[10,"20"]]`,
+		`["amount","BETWEEN",[true,false]]`,
+		`["created_at","DATE BETWEEN",["2023-02-01","2023-01-01"]]`,
+		`["created_at","DATE BETWEEN",["2023-01-01","2023-01-31","Mars/Base"]]`,
+	}
+	for _, filter := range invalid {
+		_, _, _, _, err := ParseCustomFilters(filter, "", "")
+		utils.AssertEqual(t, true, nil != err, filter)
+	}
+
+	// dialect specific containment
+	expr, _ := ParseFilter(`["meta","@>",{"vip":true}]`)
+	query, _ := expr.ToDialectSQL(DialectMySQL)
+	utils.AssertEqual(t, "JSON_CONTAINS(`meta`, ?)", query, "mysql containment")
+	_, _, err := filterCompiler{dialect: DialectSQLite}.compile(expr)
+	utils.AssertEqual(t, true, nil != err, "sqlite containment")
+
+	// schema typed values
+	schema := QuerySchema{Fields: map[string]QueryField{
+		"booked_on": {Type: QueryFieldDate, Filterable: true},
+		"amount":    {Type: QueryFieldInteger, Filterable: true},
+		"meta":      {Type: QueryFieldJSON, Filterable: true},
+	}}
+	query, params, _, _, err := schema.CustomFilters(`[["booked_on","DATE BETWEEN",["2023-01-01","2023-01-31"]],["AND"],["amount","BETWEEN",["1","5"]],["AND"],["meta","@>",["x"]]]`, "", "")
+	utils.AssertEqual(t, nil, err, "schema filters")
+	utils.AssertEqual(t, "booked_on BETWEEN ? AND ? AND amount BETWEEN ? AND ? AND meta @> ?::jsonb", query, "schema query")
+	utils.AssertEqual(t, []interface{}{"2023-01-01", "2023-01-31", int64(1), int64(5), `["x"]`}, params, "schema params")
+
+	_, _, _, _, err = schema.CustomFilters(`["amount","IS EMPTY",null]`, "", "")
+	utils.AssertEqual(t, true, nil != err, "IS EMPTY on integer field")
+}
@@ -0,0 +1,345 @@
+package lib
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/iancoleman/strcase"
+)
+
+// validators of translated database errors
+const (
+	DBErrorUnique     = "unique"
+	DBErrorRequired   = "required"
+	DBErrorForeignKey = "foreign_key"
+	DBErrorCheck      = "check"
+	DBErrorTooLong    = "too_long"
+	DBErrorDeadlock   = "deadlock"
+)
+
+// DBErrorInfo driver independent details of a database error
+type DBErrorInfo struct {
+	Driver     string // postgres, mysql, sqlite or empty when the driver error is not recognized
+	Code       string // SQLSTATE, mysql error number or sqlite extended result code
+	Constraint string // constraint or index name
+	Column     string
+	Table      string
+	Message    string // error message
+}
+
+// DBError database error translated into a field error
+type DBError struct {
+	Status    int         // http status
+	ErrorData ErrorData   // field error, Name and Path are empty when the field is unknown
+	Info      DBErrorInfo // driver error details
+	Err       error       // original error
+}
+
+// Error original error message
+func (e *DBError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap original error
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
+// DBErrorTranslator translate database error into field error and http status, ok is false when the error is not recognized
+type DBErrorTranslator func(info DBErrorInfo) (data ErrorData, status int, ok bool)
+
+// DBErrorRegistry ordered database error translators with message overrides
+type DBErrorRegistry struct {
+	mu          sync.RWMutex
+	translators []DBErrorTranslator
+	messages    map[string]string
+}
+
+// DBErrors default database error registry used by ErrorConflict
+//
+//	=> Example
+//	lib.DBErrors.Override("loyalty_account.account_number", "Loyalty Account already exists")
+//	lib.DBErrors.Register(func(info lib.DBErrorInfo) (lib.ErrorData, int, bool) {
+//		if info.Constraint != "chk_booking_dates" {
+//			return lib.ErrorData{}, 0, false
+//		}
+//		return lib.ErrorData{Name: "end_date", Path: "end_date", Validator: "gtefield", Criteria: "start_date"}, 400, true
+//	})
+var DBErrors = NewDBErrorRegistry(TranslateDBErrorCode, TranslateDBErrorMessage)
+
+// NewDBErrorRegistry create database error registry, translators are tried in order
+func NewDBErrorRegistry(translators ...DBErrorTranslator) *DBErrorRegistry {
+	return &DBErrorRegistry{translators: translators, messages: map[string]string{}}
+}
+
+// Register add translator, it is tried before the already registered translators
+func (r *DBErrorRegistry) Register(translator DBErrorTranslator) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.translators = append([]DBErrorTranslator{translator}, r.translators...)
+}
+
+// Override set message of a constraint name, field path, field name or validator, matched in that order,
+// message placeholders are the same as message catalogue
+func (r *DBErrorRegistry) Override(key, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.messages[key] = message
+}
+
+// Translate translate database error, ok is false when no translator recognizes the error
+func (r *DBErrorRegistry) Translate(err error) (*DBError, bool) {
+	if nil == err {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info := ParseDBError(err)
+	for _, translator := range r.translators {
+		if data, status, ok := translator(info); ok {
+			return &DBError{Status: status, ErrorData: data, Info: info, Err: err}, true
+		}
+	}
+
+	return nil, false
+}
+
+// Message localized message of translated error, overrides take precedence over message catalogue
+func (r *DBErrorRegistry) Message(lang string, e *DBError) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range []string{e.Info.Constraint, e.ErrorData.Path, e.ErrorData.Name, e.ErrorData.Validator} {
+		if message, ok := r.messages[key]; ok && key != "" {
+			return renderMessage(message, map[string]interface{}{"field": e.ErrorData.Name, "criteria": "", "value": ""})
+		}
+	}
+
+	return ValidationMessage(lang, e.ErrorData)
+}
+
+// dbErrorStatus http status of validators
+var dbErrorStatus = map[string]int{
+	DBErrorUnique:     http.StatusConflict,
+	DBErrorRequired:   http.StatusBadRequest,
+	DBErrorForeignKey: http.StatusConflict,
+	DBErrorCheck:      http.StatusBadRequest,
+	DBErrorTooLong:    http.StatusBadRequest,
+	DBErrorDeadlock:   http.StatusServiceUnavailable,
+}
+
+// dbErrorCodes validators of structured driver error codes
+var dbErrorCodes = map[string]map[string]string{
+	"postgres": {
+		"23505": DBErrorUnique,
+		"23502": DBErrorRequired,
+		"23503": DBErrorForeignKey,
+		"23514": DBErrorCheck,
+		"22001": DBErrorTooLong,
+		"40P01": DBErrorDeadlock,
+		"40001": DBErrorDeadlock,
+	},
+	"mysql": {
+		"1062": DBErrorUnique,
+		"1048": DBErrorRequired,
+		"1364": DBErrorRequired,
+		"1451": DBErrorForeignKey,
+		"1452": DBErrorForeignKey,
+		"3819": DBErrorCheck,
+		"1406": DBErrorTooLong,
+		"1213": DBErrorDeadlock,
+		"1205": DBErrorDeadlock,
+	},
+	"sqlite": {
+		"2067": DBErrorUnique,
+		"1555": DBErrorUnique,
+		"1299": DBErrorRequired,
+		"787":  DBErrorForeignKey,
+		"275":  DBErrorCheck,
+		"18":   DBErrorTooLong,
+		"5":    DBErrorDeadlock,
+		"6":    DBErrorDeadlock,
+		"517":  DBErrorDeadlock,
+	},
+}
+
+type sqlPatterns struct {
+	driver    string
+	pattern   *regexp.Regexp
+	validator string
+}
+
+// patterns database error messages, the first group is the field, column, constraint or index name
+var patterns []sqlPatterns = []sqlPatterns{
+	{driver: "postgres", pattern: regexp.MustCompile(`ERROR:.*duplicate key.*"([^"]+)".*`), validator: DBErrorUnique},
+	{driver: "mysql", pattern: regexp.MustCompile(`Duplicate entry.*for key '([^']+)`), validator: DBErrorUnique},
+	{driver: "sqlite", pattern: regexp.MustCompile(`UNIQUE.*:\s*(.*)`), validator: DBErrorUnique},
+	{driver: "postgres", pattern: regexp.MustCompile(`ERROR: null.*"([^"]+)".*`), validator: DBErrorRequired},
+	{driver: "mysql", pattern: regexp.MustCompile(`Column '([^']+)' cannot be null`), validator: DBErrorRequired},
+	{driver: "sqlite", pattern: regexp.MustCompile(`.*NOT NULL.*:\s*(.*)`), validator: DBErrorRequired},
+	{driver: "postgres", pattern: regexp.MustCompile(`violates foreign key constraint "([^"]+)"`), validator: DBErrorForeignKey},
+	{driver: "mysql", pattern: regexp.MustCompile("a foreign key constraint fails.*FOREIGN KEY \\(`([^`]+)`\\)"), validator: DBErrorForeignKey},
+	{driver: "sqlite", pattern: regexp.MustCompile(`FOREIGN KEY constraint failed()`), validator: DBErrorForeignKey},
+	{driver: "postgres", pattern: regexp.MustCompile(`violates check constraint "([^"]+)"`), validator: DBErrorCheck},
+	{driver: "mysql", pattern: regexp.MustCompile(`Check constraint '([^']+)' is violated`), validator: DBErrorCheck},
+	{driver: "sqlite", pattern: regexp.MustCompile(`CHECK constraint failed:\s*(.*)`), validator: DBErrorCheck},
+	{driver: "postgres", pattern: regexp.MustCompile(`value too long for type[^(]*()`), validator: DBErrorTooLong},
+	{driver: "mysql", pattern: regexp.MustCompile(`Data too long for column '([^']+)'`), validator: DBErrorTooLong},
+	{driver: "postgres", pattern: regexp.MustCompile(`deadlock detected()`), validator: DBErrorDeadlock},
+	{driver: "mysql", pattern: regexp.MustCompile(`Deadlock found()`), validator: DBErrorDeadlock},
+	{driver: "sqlite", pattern: regexp.MustCompile(`database (?:table )?is locked()`), validator: DBErrorDeadlock},
+}
+
+// indexPrefixes gorm default index and constraint name prefixes
+var indexPrefixes = regexp.MustCompile(`^(unique|index|idx|uni|fk|chk)_+`)
+
+// TranslateDBErrorCode translate structured driver error codes of postgres, mysql and sqlite drivers
+func TranslateDBErrorCode(info DBErrorInfo) (ErrorData, int, bool) {
+	validator, ok := dbErrorCodes[info.Driver][info.Code]
+	if !ok {
+		return ErrorData{}, 0, false
+	}
+
+	name := info.Column
+	if name == "" {
+		name = info.Constraint
+	}
+	if name == "" {
+		for _, pattern := range patterns {
+			if pattern.validator == validator && pattern.pattern.MatchString(info.Message) {
+				name = pattern.pattern.FindStringSubmatch(info.Message)[1]
+				break
+			}
+		}
+	}
+
+	return dbErrorData(name, validator), dbErrorStatus[validator], true
+}
+
+// TranslateDBErrorMessage translate database error messages, used when the driver error is not recognized
+func TranslateDBErrorMessage(info DBErrorInfo) (ErrorData, int, bool) {
+	for _, pattern := range patterns {
+		if match := pattern.pattern.FindStringSubmatch(info.Message); nil != match {
+			return dbErrorData(match[1], pattern.validator), dbErrorStatus[pattern.validator], true
+		}
+	}
+
+	return ErrorData{}, 0, false
+}
+
+// dbErrorData field error of column, constraint or index name
+func dbErrorData(name, validator string) ErrorData {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrorData{Validator: validator}
+	}
+
+	fieldPath := indexPrefixes.ReplaceAllString(name, "")
+	fieldPath = strings.ReplaceAll(fieldPath, "__", ".")
+	field := fieldPath
+	fields := strings.Split(field, ".")
+	if len(fields) > 1 {
+		names := []string{}
+		for _, i := range fields[1:] {
+			names = append(names, strcase.ToSnake(i))
+		}
+		field = strings.Join(names, ".")
+	}
+
+	// detect field name based on gorm default index
+	// set default gorm unique index example:
+	// `gorm:"type:varchar(36);index:,unique,where:deleted_at is null;not null"`
+	if !strings.Contains(fieldPath, ".") {
+		splits := strings.Split(fieldPath, "_")
+		splitLen := len(splits)
+		if splitLen > 2 {
+			splitIndex := (splitLen - 1) / 2
+			prefix := strings.Join(splits[0:splitIndex], "_")
+			suffixLen := len(prefix) + 1
+			if len(fieldPath) > suffixLen {
+				suffix := fieldPath[suffixLen:]
+				if strings.HasPrefix(suffix, prefix) {
+					field = suffix
+					fieldPath = prefix + "." + suffix
+				}
+			}
+		}
+	}
+
+	return ErrorData{
+		Name:      strcase.ToSnake(field),
+		Path:      fieldPath,
+		Validator: validator,
+	}
+}
+
+// ParseDBError driver independent details of database error, the error chain is inspected
+// for pgconn, lib/pq, go-sql-driver/mysql and sqlite driver errors without importing the drivers
+func ParseDBError(err error) DBErrorInfo {
+	info := DBErrorInfo{Message: err.Error()}
+
+	for e := err; nil != e; e = errors.Unwrap(e) {
+		value := reflect.ValueOf(e)
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				break
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Struct {
+			continue
+		}
+
+		switch {
+		case dbErrorField(value, "ExtendedCode") != "":
+			info.Driver = "sqlite"
+			info.Code = dbErrorField(value, "ExtendedCode")
+		case dbErrorField(value, "Number") != "":
+			info.Driver = "mysql"
+			info.Code = dbErrorField(value, "Number")
+		case len(dbErrorField(value, "Code")) == 5:
+			info.Driver = "postgres"
+			info.Code = dbErrorField(value, "Code")
+			info.Constraint = dbErrorField(value, "ConstraintName", "Constraint")
+			info.Column = dbErrorField(value, "ColumnName", "Column")
+			info.Table = dbErrorField(value, "TableName", "Table")
+		default:
+			continue
+		}
+
+		return info
+	}
+
+	return info
+}
+
+// dbErrorField string value of the first exported string or integer field
+func dbErrorField(value reflect.Value, names ...string) string {
+	for _, name := range names {
+		field := value.FieldByName(name)
+		if !field.IsValid() {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.String:
+			if field.String() != "" {
+				return field.String()
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(field.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(field.Uint(), 10)
+		}
+	}
+
+	return ""
+}
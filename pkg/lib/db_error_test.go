@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+type testPgError struct {
+	Code           string
+	Message        string
+	ConstraintName string
+	ColumnName     string
+	TableName      string
+}
+
+func (e *testPgError) Error() string { return "ERROR: " + e.Message + " (SQLSTATE " + e.Code + ")" }
+
+type testMySQLError struct {
+	Number  uint16
+	Message string
+}
+
+func (e *testMySQLError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
+
+type testSqliteError struct {
+	Code         int
+	ExtendedCode int
+	err          string
+}
+
+func (e testSqliteError) Error() string { return e.err }
+
+func TestParseDBError(t *testing.T) {
+	err := fmt.Errorf("create booking: %w", &testPgError{Code: "23503", Message: "insert violates foreign key", ConstraintName: "fk_booking_agent", TableName: "booking"})
+	info := ParseDBError(err)
+	utils.AssertEqual(t, "postgres", info.Driver, "postgres driver")
+	utils.AssertEqual(t, "23503", info.Code, "postgres code")
+	utils.AssertEqual(t, "fk_booking_agent", info.Constraint, "postgres constraint")
+	utils.AssertEqual(t, "booking", info.Table, "postgres table")
+
+	info = ParseDBError(&testMySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'idx_user_email'"})
+	utils.AssertEqual(t, "mysql", info.Driver, "mysql driver")
+	utils.AssertEqual(t, "1062", info.Code, "mysql code")
+
+	info = ParseDBError(testSqliteError{Code: 19, ExtendedCode: 2067, err: "UNIQUE constraint failed: user.email"})
+	utils.AssertEqual(t, "sqlite", info.Driver, "sqlite driver")
+	utils.AssertEqual(t, "2067", info.Code, "sqlite extended code")
+
+	info = ParseDBError(errors.New("UNIQUE: table.field_name"))
+	utils.AssertEqual(t, "", info.Driver, "unknown driver")
+}
+
+func TestDBErrorRegistry(t *testing.T) {
+	registry := NewDBErrorRegistry(TranslateDBErrorCode, TranslateDBErrorMessage)
+
+	cases := []struct {
+		err       error
+		status    int
+		validator string
+		name      string
+		path      string
+	}{
+		{&testPgError{Code: "23505", Message: "duplicate key", ConstraintName: "idx_user_user_email"}, 409, DBErrorUnique, "user_email", "user.user_email"},
+		{&testPgError{Code: "23502", Message: "null value", ColumnName: "full_name"}, 400, DBErrorRequired, "full_name", "full_name"},
+		{&testPgError{Code: "23514", Message: "check", ConstraintName: "chk_booking_total"}, 400, DBErrorCheck, "booking_total", "booking_total"},
+		{&testPgError{Code: "22001", Message: "value too long for type character varying(36)"}, 400, DBErrorTooLong, "", ""},
+		{&testPgError{Code: "40P01", Message: "deadlock detected"}, 503, DBErrorDeadlock, "", ""},
+		{&testMySQLError{Number: 1406, Message: "Data too long for column 'code' at row 1"}, 400, DBErrorTooLong, "code", "code"},
+		{&testMySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`booking`, CONSTRAINT `fk` FOREIGN KEY (`agent_id`) REFERENCES `agent` (`id`))"}, 409, DBErrorForeignKey, "agent_id", "agent_id"},
+		{testSqliteError{Code: 19, ExtendedCode: 1299, err: "NOT NULL constraint failed: user.email"}, 400, DBErrorRequired, "email", "user.email"},
+		{errors.New("UNIQUE: table.field_name"), 409, DBErrorUnique, "field_name", "table.field_name"},
+		{errors.New(`ERROR: update or delete on table "agent" violates foreign key constraint "fk_booking_agent" on table "booking" (SQLSTATE 23503)`), 409, DBErrorForeignKey, "booking_agent", "booking_agent"},
+	}
+	for i, tc := range cases {
+		dbErr, ok := registry.Translate(tc.err)
+		utils.AssertEqual(t, true, ok, fmt.Sprintf("case %d translated", i))
+		utils.AssertEqual(t, tc.status, dbErr.Status, fmt.Sprintf("case %d status", i))
+		utils.AssertEqual(t, tc.validator, dbErr.ErrorData.Validator, fmt.Sprintf("case %d validator", i))
+		utils.AssertEqual(t, tc.name, dbErr.ErrorData.Name, fmt.Sprintf("case %d name", i))
+		utils.AssertEqual(t, tc.path, dbErr.ErrorData.Path, fmt.Sprintf("case %d path", i))
+		utils.AssertEqual(t, true, errors.Is(dbErr, tc.err), fmt.Sprintf("case %d unwrap", i))
+	}
+
+	_, ok := registry.Translate(errors.New("connection refused"))
+	utils.AssertEqual(t, false, ok, "unknown error")
+
+	registry.Register(func(info DBErrorInfo) (ErrorData, int, bool) {
+		if info.Constraint != "chk_booking_dates" {
+			return ErrorData{}, 0, false
+		}
+		return ErrorData{Name: "end_date", Path: "end_date", Validator: "gtefield", Criteria: "start_date"}, 422, true
+	})
+	dbErr, _ := registry.Translate(&testPgError{Code: "23514", ConstraintName: "chk_booking_dates"})
+	utils.AssertEqual(t, 422, dbErr.Status, "registered translator")
+	utils.AssertEqual(t, "end_date must be greater than or equal start_date", registry.Message("en", dbErr), "catalogue message")
+
+	registry.Override("user.user_email", "Email {field} is already registered")
+	dbErr, _ = registry.Translate(&testPgError{Code: "23505", ConstraintName: "idx_user_user_email"})
+	utils.AssertEqual(t, "Email user_email is already registered", registry.Message("en", dbErr), "overridden message")
+}
+
+func TestErrorConflictDBError(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return ErrorConflict(c, fmt.Errorf("save: %w", &testPgError{Code: "23505", Message: "duplicate key", ColumnName: "email"}))
+	})
+	app.Get("/deadlock", func(c *fiber.Ctx) error {
+		return ErrorConflict(c, &testMySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+	})
+
+	response, body, err := GetTest(app, "/", map[string]string{fiber.HeaderAcceptLanguage: "id"})
+	utils.AssertEqual(t, nil, err, "sending request")
+	utils.AssertEqual(t, 409, response.StatusCode, "conflict")
+	errs, _ := body["error_data"].([]interface{})
+	utils.AssertEqual(t, 1, len(errs), "error data")
+	utils.AssertEqual(t, "email sudah ada", errs[0].(map[string]interface{})["message"], "localized message")
+
+	response, body, _ = GetTest(app, "/deadlock", nil)
+	utils.AssertEqual(t, 503, response.StatusCode, "deadlock")
+	utils.AssertEqual(t, nil, body["error_data"], "no field")
+}
@@ -0,0 +1,205 @@
+package lib
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestStartLocal fiber locals key of request start time, set by RequestTimer
+const requestStartLocal = "lib.request_start"
+
+// requestIDLocal fiber locals key of the fiber requestid middleware
+const requestIDLocal = "requestid"
+
+// Envelope standard success response
+type Envelope struct {
+	Status  int         `json:"status" example:"200"`      // http status
+	Message string      `json:"message" example:"success"` // response message
+	Data    interface{} `json:"data" swaggertype:"object"` // response data
+	Meta    *Meta       `json:"meta,omitempty"`            // response metadata
+	Links   *Links      `json:"links,omitempty"`           // pagination links
+}
+
+// Meta success response metadata
+type Meta struct {
+	Page      *PageMeta   `json:"page,omitempty"`                                                      // page counters
+	Cursor    *CursorMeta `json:"cursor,omitempty"`                                                    // cursor page counters
+	RequestID string      `json:"request_id,omitempty" example:"3f0c5e2a-4b1d-4c9e-9a51-2f6d3c1b7e80"` // request id
+	TookMs    *int64      `json:"took_ms,omitempty" example:"12"`                                      // processing time in milliseconds, see RequestTimer
+}
+
+// PageMeta counters of Page
+type PageMeta struct {
+	Page       int64 `json:"page" example:"0"`         // current page, start from zero
+	Size       int64 `json:"size" example:"10"`        // size per page
+	MaxPage    int64 `json:"max_page" example:"9"`     // maximum pages for current schema
+	TotalPages int64 `json:"total_pages" example:"10"` // total pages
+	Total      int64 `json:"total" example:"100"`      // total data
+	Visible    int64 `json:"visible" example:"10"`     // current length
+}
+
+// CursorMeta counters of CursorPage
+type CursorMeta struct {
+	Size    int64 `json:"size" example:"10"`        // size per page
+	HasNext bool  `json:"has_next" example:"true"`  // indicate next page exists
+	HasPrev bool  `json:"has_prev" example:"false"` // indicate previous page exists
+	Visible int64 `json:"visible" example:"10"`     // current length
+}
+
+// Links pagination links of the current request url
+type Links struct {
+	Self  string `json:"self" example:"https://api.example.com/bookings?page=1&size=10"`
+	First string `json:"first,omitempty" example:"https://api.example.com/bookings?page=0&size=10"`
+	Prev  string `json:"prev,omitempty" example:"https://api.example.com/bookings?page=0&size=10"`
+	Next  string `json:"next,omitempty" example:"https://api.example.com/bookings?page=2&size=10"`
+	Last  string `json:"last,omitempty" example:"https://api.example.com/bookings?page=9&size=10"`
+}
+
+// RequestTimer middleware which records request start time, the processing time is sent as meta.took_ms by SendData
+//
+//	app.Use(lib.RequestTimer())
+func RequestTimer() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(requestStartLocal, time.Now())
+		return c.Next()
+	}
+}
+
+// SendData send http 200 success envelope
+//
+//	=> Example
+//	return lib.SendData(c, booking) // {"status":200,"message":"success","data":{...}}
+func SendData(c *fiber.Ctx, data interface{}, message ...string) error {
+	return sendEnvelope(c, NewEnvelope(c, data, message...))
+}
+
+// SendPage send http 200 success envelope of page items with page counters and links
+//
+//	=> Example
+//	page, err := lib.Paginate(db, &model.Booking{}, &bookings, lib.GetListQuery(c))
+//	...
+//	return lib.SendPage(c, page) // {"data":[...],"meta":{"page":{...}},"links":{"self":...,"next":...}}
+func SendPage(c *fiber.Ctx, page Page, message ...string) error {
+	envelope := NewEnvelope(c, page.Items, message...)
+	envelope.Meta.Page = &PageMeta{
+		Page:       page.Page,
+		Size:       page.Size,
+		MaxPage:    page.MaxPage,
+		TotalPages: page.TotalPages,
+		Total:      page.Total,
+		Visible:    page.Visible,
+	}
+	envelope.Links = PageLinks(c, page)
+
+	return sendEnvelope(c, envelope)
+}
+
+// SendCursorPage send http 200 success envelope of cursor page items with counters and links
+func SendCursorPage(c *fiber.Ctx, page CursorPage, message ...string) error {
+	envelope := NewEnvelope(c, page.Items, message...)
+	envelope.Meta.Cursor = &CursorMeta{
+		Size:    page.Size,
+		HasNext: page.HasNext,
+		HasPrev: page.HasPrev,
+		Visible: page.Visible,
+	}
+	envelope.Links = CursorPageLinks(c, page)
+
+	return sendEnvelope(c, envelope)
+}
+
+// NewEnvelope create success envelope with request id and processing time
+func NewEnvelope(c *fiber.Ctx, data interface{}, message ...string) Envelope {
+	envelope := Envelope{
+		Status:  200,
+		Message: "success",
+		Data:    data,
+		Meta:    &Meta{RequestID: requestID(c)},
+	}
+	if len(message) > 0 {
+		envelope.Message = message[0]
+	}
+	if start, ok := c.Locals(requestStartLocal).(time.Time); ok {
+		took := time.Since(start).Milliseconds()
+		envelope.Meta.TookMs = &took
+	}
+
+	return envelope
+}
+
+// PageLinks self, first, prev, next and last links of page
+func PageLinks(c *fiber.Ctx, page Page) *Links {
+	pageURL := func(number int64) string {
+		return requestURL(c, map[string]string{"page": strconv.FormatInt(number, 10)})
+	}
+
+	links := &Links{
+		Self:  c.BaseURL() + c.OriginalURL(),
+		First: pageURL(0),
+		Last:  pageURL(page.MaxPage),
+	}
+	if !page.First && page.Page > 0 {
+		links.Prev = pageURL(page.Page - 1)
+	}
+	if !page.Last {
+		links.Next = pageURL(page.Page + 1)
+	}
+
+	return links
+}
+
+// CursorPageLinks self, first, prev and next links of cursor page
+func CursorPageLinks(c *fiber.Ctx, page CursorPage) *Links {
+	links := &Links{
+		Self:  c.BaseURL() + c.OriginalURL(),
+		First: requestURL(c, map[string]string{"cursor": ""}),
+	}
+	if page.HasPrev && page.PrevCursor != "" {
+		links.Prev = requestURL(c, map[string]string{"cursor": page.PrevCursor})
+	}
+	if page.HasNext && page.NextCursor != "" {
+		links.Next = requestURL(c, map[string]string{"cursor": page.NextCursor})
+	}
+
+	return links
+}
+
+// requestURL current request url with replaced query parameters, empty value removes the parameter
+func requestURL(c *fiber.Ctx, params map[string]string) string {
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	for key, value := range params {
+		if value == "" {
+			query.Del(key)
+			continue
+		}
+		query.Set(key, value)
+	}
+
+	link := c.BaseURL() + c.Path()
+	if encoded := query.Encode(); encoded != "" {
+		link += "?" + encoded
+	}
+	return link
+}
+
+// requestID request id of fiber requestid middleware or X-Request-ID header
+func requestID(c *fiber.Ctx) string {
+	if id, ok := c.Locals(requestIDLocal).(string); ok && id != "" {
+		return id
+	}
+	if id := c.GetRespHeader(fiber.HeaderXRequestID); id != "" {
+		return id
+	}
+	return c.Get(fiber.HeaderXRequestID)
+}
+
+// sendEnvelope send envelope, metadata is omitted when empty
+func sendEnvelope(c *fiber.Ctx, envelope Envelope) error {
+	if *envelope.Meta == (Meta{}) {
+		envelope.Meta = nil
+	}
+	return c.Status(envelope.Status).JSON(envelope)
+}
@@ -0,0 +1,70 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestSendData(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestTimer())
+	app.Get("/bookings/:id", func(c *fiber.Ctx) error {
+		return SendData(c, map[string]interface{}{"id": c.Params("id")})
+	})
+	app.Get("/plain", func(c *fiber.Ctx) error {
+		c.Locals(requestStartLocal, nil)
+		return SendData(c, "pong", "pong")
+	})
+
+	response, body, err := GetTest(app, "/bookings/1", map[string]string{fiber.HeaderXRequestID: "req-1"})
+	utils.AssertEqual(t, nil, err, "sending request")
+	utils.AssertEqual(t, 200, response.StatusCode, "status code")
+	utils.AssertEqual(t, float64(200), body["status"], "status")
+	utils.AssertEqual(t, "success", body["message"], "message")
+	utils.AssertEqual(t, "1", body["data"].(map[string]interface{})["id"], "data")
+	meta := body["meta"].(map[string]interface{})
+	utils.AssertEqual(t, "req-1", meta["request_id"], "request id")
+	utils.AssertEqual(t, true, nil != meta["took_ms"], "timing")
+	utils.AssertEqual(t, nil, body["links"], "no links")
+
+	_, body, _ = GetTest(app, "/plain", nil)
+	utils.AssertEqual(t, "pong", body["message"], "custom message")
+	utils.AssertEqual(t, nil, body["meta"], "empty meta is omitted")
+}
+
+func TestSendPage(t *testing.T) {
+	app := fiber.New()
+	app.Get("/bookings", func(c *fiber.Ctx) error {
+		query := GetListQuery(c)
+		return SendPage(c, NewPage([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, query.Page, query.Size, 35))
+	})
+	app.Get("/cursor", func(c *fiber.Ctx) error {
+		return SendCursorPage(c, CursorPage{Items: []int{1, 2}, Size: 2, NextCursor: "next-token", HasNext: true, Visible: 2})
+	})
+
+	_, body, err := GetTest(app, "/bookings?size=10&page=1&search=bali", nil)
+	utils.AssertEqual(t, nil, err, "sending request")
+	utils.AssertEqual(t, 10, len(body["data"].([]interface{})), "page items")
+	page := body["meta"].(map[string]interface{})["page"].(map[string]interface{})
+	utils.AssertEqual(t, float64(35), page["total"], "total")
+	utils.AssertEqual(t, float64(3), page["max_page"], "max page")
+	links := body["links"].(map[string]interface{})
+	utils.AssertEqual(t, "http://example.com/bookings?size=10&page=1&search=bali", links["self"], "self link")
+	utils.AssertEqual(t, "http://example.com/bookings?page=0&search=bali&size=10", links["first"], "first link")
+	utils.AssertEqual(t, "http://example.com/bookings?page=0&search=bali&size=10", links["prev"], "prev link")
+	utils.AssertEqual(t, "http://example.com/bookings?page=2&search=bali&size=10", links["next"], "next link")
+	utils.AssertEqual(t, "http://example.com/bookings?page=3&search=bali&size=10", links["last"], "last link")
+
+	_, body, _ = GetTest(app, "/bookings?page=3", nil)
+	links = body["links"].(map[string]interface{})
+	utils.AssertEqual(t, nil, links["next"], "no next link on last page")
+
+	_, body, _ = GetTest(app, "/cursor?size=2&cursor=current", nil)
+	links = body["links"].(map[string]interface{})
+	utils.AssertEqual(t, "http://example.com/cursor?size=2", links["first"], "cursor first link")
+	utils.AssertEqual(t, "http://example.com/cursor?cursor=next-token&size=2", links["next"], "cursor next link")
+	utils.AssertEqual(t, nil, links["prev"], "no cursor prev link")
+	utils.AssertEqual(t, true, body["meta"].(map[string]interface{})["cursor"].(map[string]interface{})["has_next"], "cursor meta")
+}
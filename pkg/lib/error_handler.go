@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ErrorHandler fiber error handler which sends any returned error as lib.Response
+//
+//	=> Example
+//	app := fiber.New(fiber.Config{ErrorHandler: lib.ErrorHandler})
+//	app.Get("/bookings/:id", func(c *fiber.Ctx) error {
+//		booking := model.Booking{}
+//		if err := db.First(&booking, "id = ?", c.Params("id")).Error; nil != err {
+//			return err // 404 on gorm.ErrRecordNotFound, 409 or 400 on constraint violations
+//		}
+//		return lib.OK(c, booking)
+//	})
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var errorResponse ErrorResponse
+	if errors.As(err, &errorResponse) {
+		return errorResponse.SendToContext(c)
+	}
+
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		return Send(c, fiberError.Code, fiberError.Message)
+	}
+
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return ErrorBadRequest(c, validationErrors)
+	}
+
+	var errorDataProvider ErrorDataProvider
+	if errors.As(err, &errorDataProvider) {
+		return ErrorBadRequest(c, err)
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrorNotFound(c)
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTimeout(c)
+	}
+
+	if dbErr, ok := DBErrors.Translate(err); ok {
+		return sendDBError(c, dbErr)
+	}
+
+	log.Printf("ErrorHandler, %s %s: %s", c.Method(), c.OriginalURL(), err.Error())
+	return ErrorInternal(c)
+}
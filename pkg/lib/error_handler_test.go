@@ -0,0 +1,69 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"gorm.io/gorm"
+)
+
+func TestErrorHandler(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/error-response", func(c *fiber.Ctx) error {
+		return fmt.Errorf("wrapped: %w", SetErrorForbidden("Access denied"))
+	})
+	app.Get("/fiber", func(c *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusMethodNotAllowed, "Method not allowed")
+	})
+	app.Get("/validation", func(c *fiber.Ctx) error {
+		return VALIDATOR.Struct(payload{})
+	})
+	app.Get("/filters", func(c *fiber.Ctx) error {
+		_, err := ParseFilter(`["status","EQUALS","paid"]`)
+		return err
+	})
+	app.Get("/not-found", func(c *fiber.Ctx) error {
+		return fmt.Errorf("find booking: %w", gorm.ErrRecordNotFound)
+	})
+	app.Get("/timeout", func(c *fiber.Ctx) error {
+		return context.DeadlineExceeded
+	})
+	app.Get("/duplicate", func(c *fiber.Ctx) error {
+		return errors.New("UNIQUE constraint failed: booking.code")
+	})
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+
+	tests := []struct {
+		path    string
+		code    int
+		message string
+	}{
+		{"/error-response", 403, "Access denied"},
+		{"/fiber", 405, "Method not allowed"},
+		{"/validation", 400, "Bad Request"},
+		{"/filters", 400, ""},
+		{"/not-found", 404, "Not found"},
+		{"/timeout", 408, "Request Timeout"},
+		{"/duplicate", 409, "Conflict"},
+		{"/internal", 500, "Internal server error"},
+		{"/unknown-route", 404, "Cannot GET /unknown-route"},
+	}
+	for _, tt := range tests {
+		response, body, err := GetTest(app, tt.path, nil)
+		utils.AssertEqual(t, nil, err, "sending request")
+		utils.AssertEqual(t, tt.code, response.StatusCode, tt.path+" status")
+		if tt.message != "" {
+			utils.AssertEqual(t, tt.message, body["message"], tt.path+" message")
+		}
+	}
+}
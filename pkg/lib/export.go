@@ -0,0 +1,343 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// MIMEApplicationNDJSON newline delimited json content type
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// export formats of ExportOptions
+const (
+	ExportNDJSON = "ndjson" // default, one json object per line
+	ExportCSV    = "csv"
+)
+
+// exportFlushRows rows written before the stream is flushed to the client
+const exportFlushRows = 100
+
+// ExportIncomplete marker of a stream failing after the 200 status is sent, the error is logged and
+// NDJSON ends with the line {"error":"export incomplete"}, CSV ends with the single cell record "# export incomplete",
+// clients must check the last line before trusting the export
+const ExportIncomplete = "export incomplete"
+
+// ExportColumn exported column
+type ExportColumn struct {
+	Field  string // dotted path of the row, example: agent.name, see ObjectToSingleLevel
+	Header string // CSV header and NDJSON key, default Field
+}
+
+// ExportOptions streaming export options
+type ExportOptions struct {
+	Format        string         // ExportNDJSON or ExportCSV
+	Filename      string         // attachment file name, example: bookings.csv, empty sends the rows inline
+	Columns       []ExportColumn // exported columns, empty exports every top level field
+	AllowFormulas bool           // write CSV cells starting with = + - @ tab or CR as is, by default they are prefixed with ' against formula injection unless they are numbers
+}
+
+// ExportColumns export columns of fields, headers are the field names
+//
+//	lib.ExportColumns("id", "booking_code", "agent.name")
+func ExportColumns(fields ...string) []ExportColumn {
+	columns := []ExportColumn{}
+	for _, field := range fields {
+		columns = append(columns, ExportColumn{Field: field})
+	}
+	return columns
+}
+
+// StreamRows stream gorm rows cursor as NDJSON or CSV, rows are closed when the stream ends,
+// scan errors end the stream with the ExportIncomplete marker
+//
+//	=> Example
+//	rows, err := db.Model(&model.Booking{}).Scopes(lib.ListScope(query)).Rows()
+//	if nil != err {
+//		return lib.ErrorInternal(c, err.Error())
+//	}
+//	return lib.StreamRows(c, db, rows, lib.ExportOptions{
+//		Format:   lib.ExportCSV,
+//		Filename: "bookings.csv",
+//		Columns:  lib.ExportColumns("booking_code", "total_price", "created_at"),
+//	})
+func StreamRows(c *fiber.Ctx, db *gorm.DB, rows *sql.Rows, options ExportOptions) error {
+	next := func() (interface{}, bool, error) {
+		if !rows.Next() {
+			return nil, false, rows.Err()
+		}
+		row := map[string]interface{}{}
+		if err := db.ScanRows(rows, &row); nil != err {
+			return nil, false, err
+		}
+		for column, value := range row {
+			if b, ok := value.([]byte); ok {
+				row[column] = string(b) // text columns of some drivers
+			}
+		}
+		return row, true, nil
+	}
+
+	return streamExport(c, options, next, func() { rows.Close() })
+}
+
+// StreamChannel stream items of channel as NDJSON or CSV until the channel is closed,
+// items are drained when the client disconnects so the producer is never blocked
+//
+//	=> Example
+//	items := make(chan interface{})
+//	go func() {
+//		defer close(items)
+//		for _, booking := range bookings {
+//			items <- booking
+//		}
+//	}()
+//	return lib.StreamChannel(c, items, lib.ExportOptions{Filename: "bookings.ndjson"})
+func StreamChannel(c *fiber.Ctx, items <-chan interface{}, options ExportOptions) error {
+	next := func() (interface{}, bool, error) {
+		item, ok := <-items
+		return item, ok, nil
+	}
+	drain := func() {
+		go func() {
+			for range items {
+			}
+		}()
+	}
+
+	return streamExport(c, options, next, drain)
+}
+
+// streamExport stream rows of next into response body, done is called once the stream ends,
+// errors after the 200 status are logged and reported by the ExportIncomplete marker line
+func streamExport(c *fiber.Ctx, options ExportOptions, next func() (interface{}, bool, error), done func()) error {
+	contentType := MIMEApplicationNDJSON
+	if options.Format == ExportCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	if options.Filename != "" {
+		c.Attachment(options.Filename)
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+
+	c.Status(200).Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer done()
+
+		var writer exportWriter = &ndjsonWriter{w: w, columns: options.Columns}
+		if options.Format == ExportCSV {
+			writer = &csvWriter{w: csv.NewWriter(w), columns: options.Columns, allowFormulas: options.AllowFormulas}
+		}
+
+		for count := 1; ; count++ {
+			item, ok, err := next()
+			if nil == err && !ok {
+				break
+			}
+			if nil == err {
+				err = writer.Write(item)
+			}
+			if nil != err {
+				log.Printf("StreamExport, %s", err.Error())
+				writer.Fail()
+				writer.Flush()
+				return
+			}
+			if count%exportFlushRows == 0 {
+				if err := writer.Flush(); nil != err {
+					return // client disconnected
+				}
+			}
+		}
+		writer.Flush()
+	})
+
+	return nil
+}
+
+type exportWriter interface {
+	Write(item interface{}) error
+	Fail() error // write the ExportIncomplete marker
+	Flush() error
+}
+
+// ndjsonWriter write items as json lines, only columns are written when they are defined
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	columns []ExportColumn
+}
+
+func (n *ndjsonWriter) Write(item interface{}) error {
+	if len(n.columns) > 0 {
+		row, err := exportRow(item)
+		if nil != err {
+			return err
+		}
+		line := map[string]interface{}{}
+		for _, column := range n.columns {
+			line[column.header()] = exportValue(row, column.Field)
+		}
+		item = line
+	}
+
+	encoder := json.NewEncoder(n.w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(item) // Encode appends the newline
+}
+
+func (n *ndjsonWriter) Fail() error {
+	return json.NewEncoder(n.w).Encode(map[string]string{"error": ExportIncomplete})
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return n.w.Flush()
+}
+
+// csvWriter write items as CSV records, header is written before the first record
+type csvWriter struct {
+	w             *csv.Writer
+	columns       []ExportColumn
+	started       bool
+	allowFormulas bool
+}
+
+func (c *csvWriter) Write(item interface{}) error {
+	row, err := exportRow(item)
+	if nil != err {
+		return err
+	}
+
+	if !c.started {
+		c.started = true
+		if len(c.columns) == 0 {
+			fields := []string{}
+			for field := range row {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			c.columns = ExportColumns(fields...)
+		}
+		header := []string{}
+		for _, column := range c.columns {
+			header = append(header, escapeFormula(column.header()))
+		}
+		if err := c.w.Write(header); nil != err {
+			return err
+		}
+	}
+
+	record := []string{}
+	for _, column := range c.columns {
+		cell := exportString(exportValue(row, column.Field))
+		if !c.allowFormulas {
+			cell = escapeFormula(cell)
+		}
+		record = append(record, cell)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Fail() error {
+	return c.w.Write([]string{"# " + ExportIncomplete})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c ExportColumn) header() string {
+	if c.Header != "" {
+		return c.Header
+	}
+	return c.Field
+}
+
+// exportRow convert item into map, numbers are kept as json.Number to avoid float formatting
+func exportRow(item interface{}) (map[string]interface{}, error) {
+	if row, ok := item.(map[string]interface{}); ok {
+		return row, nil
+	}
+
+	content, err := JSONMarshal(item)
+	if nil != err {
+		return nil, err
+	}
+	row := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&row); nil != err {
+		return nil, fmt.Errorf("export row must be an object: %s", err.Error())
+	}
+	return row, nil
+}
+
+// exportValue value of dotted path, the parent is looked up first like ObjectToSingleLevel
+func exportValue(row map[string]interface{}, field string) interface{} {
+	if value, ok := row[field]; ok {
+		return value
+	}
+	parent, child, found := strings.Cut(field, ".")
+	if !found {
+		return nil
+	}
+	source, ok := row[parent].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return exportValue(source, child)
+}
+
+// exportString CSV representation of value
+func exportString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if nil == v {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case map[string]interface{}, []interface{}:
+		content := strings.Builder{}
+		encoder := json.NewEncoder(&content)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(v)
+		return strings.TrimSuffix(content.String(), "\n")
+	}
+	return fmt.Sprint(value)
+}
+
+// escapeFormula prefix cells which spreadsheets evaluate as formula with a quote, numbers like -150.5 are kept
+func escapeFormula(cell string) string {
+	if _, err := strconv.ParseFloat(cell, 64); nil == err {
+		return cell
+	}
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -0,0 +1,165 @@
+package lib
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// exportTestDriver sql driver which returns fixed booking rows
+type exportTestDriver struct{}
+
+func (exportTestDriver) Open(string) (driver.Conn, error) { return exportTestConn{}, nil }
+
+type exportTestConn struct{}
+
+func (exportTestConn) Prepare(string) (driver.Stmt, error) { return exportTestStmt{}, nil }
+func (exportTestConn) Close() error                        { return nil }
+func (exportTestConn) Begin() (driver.Tx, error)           { return nil, driver.ErrSkip }
+
+type exportTestStmt struct{}
+
+func (exportTestStmt) Close() error                               { return nil }
+func (exportTestStmt) NumInput() int                              { return -1 }
+func (exportTestStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (exportTestStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &exportTestRows{values: [][]driver.Value{
+		{"BK-1", int64(1500000), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"BK-2", int64(25), nil},
+	}}, nil
+}
+
+type exportTestRows struct {
+	values [][]driver.Value
+	index  int
+}
+
+func (r *exportTestRows) Columns() []string { return []string{"code", "total_price", "paid_at"} }
+func (r *exportTestRows) Close() error      { return nil }
+func (r *exportTestRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.index])
+	r.index++
+	return nil
+}
+
+func init() {
+	sql.Register("lib-export-test", exportTestDriver{})
+}
+
+func TestStreamRows(t *testing.T) {
+	db, _ := dryRunDB(t)
+	sqlDB, err := sql.Open("lib-export-test", "")
+	utils.AssertEqual(t, nil, err, "open test database")
+
+	app := fiber.New()
+	app.Get("/bookings.csv", func(c *fiber.Ctx) error {
+		rows, err := sqlDB.Query("SELECT code, total_price, paid_at FROM booking")
+		if nil != err {
+			return err
+		}
+		return StreamRows(c, db, rows, ExportOptions{
+			Format:   ExportCSV,
+			Filename: "bookings.csv",
+			Columns:  []ExportColumn{{Field: "code", Header: "Booking Code"}, {Field: "total_price"}, {Field: "paid_at"}},
+		})
+	})
+
+	response, err := app.Test(httptest.NewRequest("GET", "/bookings.csv", nil))
+	utils.AssertEqual(t, nil, err, "sending request")
+	body, _ := io.ReadAll(response.Body)
+	utils.AssertEqual(t, "text/csv; charset=utf-8", response.Header.Get(fiber.HeaderContentType), "content type")
+	utils.AssertEqual(t, `attachment; filename="bookings.csv"`, response.Header.Get(fiber.HeaderContentDisposition), "content disposition")
+	utils.AssertEqual(t, "Booking Code,total_price,paid_at\nBK-1,1500000,2024-01-02T03:04:05Z\nBK-2,25,\n", string(body), "csv body")
+}
+
+func TestStreamChannel(t *testing.T) {
+	type agent struct {
+		Name string `json:"name"`
+	}
+	type booking struct {
+		Code   string  `json:"code"`
+		Amount float64 `json:"amount"`
+		Agent  agent   `json:"agent"`
+	}
+
+	newItems := func() <-chan interface{} {
+		items := make(chan interface{})
+		go func() {
+			defer close(items)
+			items <- booking{Code: "BK-1", Amount: 1500000.5, Agent: agent{Name: "Bali <Tour>"}}
+			items <- map[string]interface{}{"code": "BK-2", "amount": 10, "agent": map[string]interface{}{"name": "Java"}}
+		}()
+		return items
+	}
+
+	app := fiber.New()
+	app.Get("/ndjson", func(c *fiber.Ctx) error {
+		return StreamChannel(c, newItems(), ExportOptions{Columns: ExportColumns("code", "agent.name")})
+	})
+	app.Get("/csv", func(c *fiber.Ctx) error {
+		return StreamChannel(c, newItems(), ExportOptions{Format: ExportCSV})
+	})
+
+	response, err := app.Test(httptest.NewRequest("GET", "/ndjson", nil))
+	utils.AssertEqual(t, nil, err, "sending request")
+	body, _ := io.ReadAll(response.Body)
+	utils.AssertEqual(t, MIMEApplicationNDJSON, response.Header.Get(fiber.HeaderContentType), "content type")
+	utils.AssertEqual(t, "", response.Header.Get(fiber.HeaderContentDisposition), "inline")
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	utils.AssertEqual(t, 2, len(lines), "ndjson lines")
+	utils.AssertEqual(t, `{"agent.name":"Bali <Tour>","code":"BK-1"}`, lines[0], "ndjson columns")
+
+	response, _ = app.Test(httptest.NewRequest("GET", "/csv", nil))
+	body, _ = io.ReadAll(response.Body)
+	utils.AssertEqual(t, "agent,amount,code\n\"{\"\"name\"\":\"\"Bali <Tour>\"\"}\",1500000.5,BK-1\n\"{\"\"name\"\":\"\"Java\"\"}\",10,BK-2\n", string(body), "csv without columns")
+}
+
+func TestStreamExportSafety(t *testing.T) {
+	newItems := func() <-chan interface{} {
+		items := make(chan interface{})
+		go func() {
+			defer close(items)
+			items <- map[string]interface{}{"code": "=HYPERLINK(\"http://evil\")", "note": "-1"}
+			items <- map[string]interface{}{"code": "-2+3+cmd|' /C calc'!A0", "note": -150.5}
+			items <- "not an object"
+			items <- map[string]interface{}{"code": "never sent"}
+		}()
+		return items
+	}
+
+	app := fiber.New()
+	app.Get("/csv", func(c *fiber.Ctx) error {
+		return StreamChannel(c, newItems(), ExportOptions{Format: ExportCSV, Columns: ExportColumns("code", "note")})
+	})
+	app.Get("/formulas", func(c *fiber.Ctx) error {
+		return StreamChannel(c, newItems(), ExportOptions{Format: ExportCSV, Columns: ExportColumns("code", "note"), AllowFormulas: true})
+	})
+	app.Get("/ndjson", func(c *fiber.Ctx) error {
+		return StreamChannel(c, newItems(), ExportOptions{Columns: ExportColumns("code")})
+	})
+
+	response, err := app.Test(httptest.NewRequest("GET", "/csv", nil))
+	utils.AssertEqual(t, nil, err, "sending request")
+	body, _ := io.ReadAll(response.Body)
+	utils.AssertEqual(t, "code,note\n\"'=HYPERLINK(\"\"http://evil\"\")\",-1\n'-2+3+cmd|' /C calc'!A0,-150.5\n# export incomplete\n", string(body), "escaped formulas, negative numbers and error marker")
+
+	response, _ = app.Test(httptest.NewRequest("GET", "/formulas", nil))
+	body, _ = io.ReadAll(response.Body)
+	utils.AssertEqual(t, "code,note\n\"=HYPERLINK(\"\"http://evil\"\")\",-1\n-2+3+cmd|' /C calc'!A0,-150.5\n# export incomplete\n", string(body), "formulas allowed")
+
+	response, _ = app.Test(httptest.NewRequest("GET", "/ndjson", nil))
+	body, _ = io.ReadAll(response.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	utils.AssertEqual(t, 3, len(lines), "ndjson lines")
+	utils.AssertEqual(t, `{"error":"export incomplete"}`, lines[2], "ndjson error line")
+}
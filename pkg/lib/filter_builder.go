@@ -0,0 +1,254 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FilterBuilder fluent builder of the filter DSL parsed by CreateFilter and ParseFilter,
+// conditions are combined from left to right instead of AND before OR,
+// switching between AND and OR wraps the conditions so far into a group
+//
+//	NewFilter().Where("a", "=", 1).Or("b", "=", 2).Where("tenant_id", "=", 3) // (a OR b) AND tenant_id
+//
+//	=> Example
+//	filters, err := lib.NewFilter().
+//		Where("status", "IN", []string{"paid", "due"}).
+//		Group(lib.NewFilter().Where("agent_id", "=", agentID).Or("corporate_id", "=", corporateID)).
+//		Build()
+//	// [["status","IN",["paid","due"]],["AND"],[["agent_id","=","A"],["OR"],["corporate_id","=","B"]]]
+type FilterBuilder struct {
+	items []interface{}
+	logic string // logic joining items, empty while there is a single item
+	err   error
+}
+
+// NewFilter create filter builder
+func NewFilter() *FilterBuilder {
+	return &FilterBuilder{items: []interface{}{}}
+}
+
+// Where add condition joined with AND
+func (b *FilterBuilder) Where(field, operator string, value interface{}) *FilterBuilder {
+	return b.add("AND", b.condition(field, operator, value))
+}
+
+// And add condition joined with AND
+func (b *FilterBuilder) And(field, operator string, value interface{}) *FilterBuilder {
+	return b.Where(field, operator, value)
+}
+
+// Or add condition joined with OR
+func (b *FilterBuilder) Or(field, operator string, value interface{}) *FilterBuilder {
+	return b.add("OR", b.condition(field, operator, value))
+}
+
+// Group add nested group joined with AND
+func (b *FilterBuilder) Group(group *FilterBuilder) *FilterBuilder {
+	return b.add("AND", b.group(group))
+}
+
+// OrGroup add nested group joined with OR
+func (b *FilterBuilder) OrGroup(group *FilterBuilder) *FilterBuilder {
+	return b.add("OR", b.group(group))
+}
+
+// Not add negated group joined with AND
+func (b *FilterBuilder) Not(group *FilterBuilder) *FilterBuilder {
+	if items := b.group(group); nil != items {
+		return b.add("AND", []interface{}{"NOT", items})
+	}
+	return b
+}
+
+// OrNot add negated group joined with OR
+func (b *FilterBuilder) OrNot(group *FilterBuilder) *FilterBuilder {
+	if items := b.group(group); nil != items {
+		return b.add("OR", []interface{}{"NOT", items})
+	}
+	return b
+}
+
+// IsEmpty check whether builder contains any condition
+func (b *FilterBuilder) IsEmpty() bool {
+	return len(b.items) == 0
+}
+
+// String filter DSL in wire format, empty when builder has no condition or is invalid
+func (b *FilterBuilder) String() string {
+	filters, _ := b.Build()
+	return filters
+}
+
+// Encode URL encoded filter DSL
+func (b *FilterBuilder) Encode() string {
+	return url.QueryEscape(b.String())
+}
+
+// Build filter DSL in wire format, the result is validated by parsing it back
+func (b *FilterBuilder) Build() (string, error) {
+	if nil != b.err {
+		return "", b.err
+	}
+	if b.IsEmpty() {
+		return "", nil
+	}
+
+	// operators like > and < are kept as is instead of HTML escaped
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(b.items); nil != err {
+		return "", err
+	}
+	filters := strings.TrimSuffix(buffer.String(), "\n")
+	if _, err := ParseFilter(filters); nil != err {
+		return "", err
+	}
+
+	return filters, nil
+}
+
+// Expr parse built filter into filter expression
+func (b *FilterBuilder) Expr() (FilterExpr, error) {
+	filters, err := b.Build()
+	if nil != err {
+		return FilterExpr{}, err
+	}
+	return ParseFilter(filters)
+}
+
+func (b *FilterBuilder) add(logic string, element interface{}) *FilterBuilder {
+	if nil == element {
+		return b
+	}
+	if len(b.items) == 0 {
+		b.items = append(b.items, element)
+		return b
+	}
+	if b.logic != "" && b.logic != logic {
+		b.items = []interface{}{b.items}
+	}
+	b.logic = logic
+	b.items = append(b.items, []interface{}{logic}, element)
+	return b
+}
+
+func (b *FilterBuilder) condition(field, operator string, value interface{}) interface{} {
+	if strings.TrimSpace(field) == "" {
+		b.fail(errors.New("filter field name must not be empty"))
+		return nil
+	}
+	if operator == "" {
+		operator = "="
+	}
+	return []interface{}{field, operator, value}
+}
+
+func (b *FilterBuilder) group(group *FilterBuilder) interface{} {
+	if nil == group {
+		return nil
+	}
+	if nil != group.err {
+		b.fail(group.err)
+		return nil
+	}
+	if group.IsEmpty() {
+		return nil
+	}
+	return group.items
+}
+
+func (b *FilterBuilder) fail(err error) {
+	if nil == b.err {
+		b.err = err
+	}
+}
+
+// SortBuilder fluent builder of the sort parameter parsed by Sorting
+//
+//	=> Example
+//	lib.NewSort().Desc("created_at").Asc("name").String() // -created_at,name
+type SortBuilder struct {
+	fields []string
+	err    error
+}
+
+// NewSort create sort builder
+func NewSort() *SortBuilder {
+	return &SortBuilder{fields: []string{}}
+}
+
+// Asc sort field ascending
+func (s *SortBuilder) Asc(field string) *SortBuilder {
+	return s.add(field)
+}
+
+// Desc sort field descending
+func (s *SortBuilder) Desc(field string) *SortBuilder {
+	return s.add("-" + field)
+}
+
+// String sort parameter in wire format, empty when builder is invalid
+func (s *SortBuilder) String() string {
+	sort, _ := s.Build()
+	return sort
+}
+
+// Encode URL encoded sort parameter
+func (s *SortBuilder) Encode() string {
+	return url.QueryEscape(s.String())
+}
+
+// Build sort parameter in wire format
+func (s *SortBuilder) Build() (string, error) {
+	if nil != s.err {
+		return "", s.err
+	}
+	return strings.Join(s.fields, ","), nil
+}
+
+func (s *SortBuilder) add(field string) *SortBuilder {
+	name := strings.TrimPrefix(field, "-")
+	// Sorting splits fields by comma and direction by dash
+	if strings.TrimSpace(name) == "" || strings.ContainsAny(name, ", -") {
+		if nil == s.err {
+			s.err = fmt.Errorf("invalid sort field %q", name)
+		}
+		return s
+	}
+	s.fields = append(s.fields, field)
+	return s
+}
+
+// Encode URL encoded list query parameters, empty parameters are omitted
+//
+//	=> Example
+//	query := lib.ListQuery{Size: 50, Sort: lib.NewSort().Desc("created_at").String(), Filters: filters}
+//	client.SetURL(baseURL + "/bookings?" + query.Encode())
+func (q ListQuery) Encode() string {
+	values := url.Values{}
+	if q.Page > 0 {
+		values.Set("page", strconv.FormatInt(q.Page, 10))
+	}
+	if q.Size > 0 {
+		values.Set("size", strconv.FormatInt(q.Size, 10))
+	}
+	for name, value := range map[string]string{
+		"sort":    q.Sort,
+		"filters": q.Filters,
+		"search":  q.Search,
+		"columns": q.Columns,
+	} {
+		if value != "" {
+			values.Set(name, value)
+		}
+	}
+
+	return values.Encode()
+}
@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestFilterBuilder(t *testing.T) {
+	filters, err := NewFilter().
+		Where("status", "IN", []string{"paid", "due"}).
+		Group(NewFilter().Where("agent_id", "=", "A").Or("corporate_id", "=", "B")).
+		Not(NewFilter().Where("deleted_at", "IS NOT", nil)).
+		Build()
+	utils.AssertEqual(t, nil, err, "build")
+	utils.AssertEqual(t, `[["status","IN",["paid","due"]],["AND"],[["agent_id","=","A"],["OR"],["corporate_id","=","B"]],["AND"],["NOT",[["deleted_at","IS NOT",null]]]]`, filters, "wire format")
+
+	// round trip through the parser and the legacy compiler
+	query, params, _, _, err := ParseCustomFilters(filters, "", "")
+	utils.AssertEqual(t, nil, err, "parse")
+	utils.AssertEqual(t, "status IN ? AND (agent_id = ? OR corporate_id = ?) AND NOT ((deleted_at IS NOT NULL))", query, "query")
+	utils.AssertEqual(t, 3, len(params), "params")
+
+	// left to right, the tenant condition applies to both conditions before it
+	filters, err = NewFilter().Where("status", "=", "paid").Or("status", "=", "due").Where("tenant_id", "=", "T").Build()
+	utils.AssertEqual(t, nil, err, "build")
+	utils.AssertEqual(t, `[[["status","=","paid"],["OR"],["status","=","due"]],["AND"],["tenant_id","=","T"]]`, filters, "or wrapped before and")
+	query, _, _, _, _ = ParseCustomFilters(filters, "", "")
+	utils.AssertEqual(t, "(status = ? OR status = ?) AND tenant_id = ?", query, "or precedence")
+
+	filters, _ = NewFilter().Where("a", "=", 1).And("b", "=", 2).Or("c", "=", 3).Or("d", "=", 4).Build()
+	utils.AssertEqual(t, `[[["a","=",1],["AND"],["b","=",2]],["OR"],["c","=",3],["OR"],["d","=",4]]`, filters, "and wrapped before or")
+
+	encoded := NewFilter().Where("amount", ">=", 10).Encode()
+	decoded, _ := url.QueryUnescape(encoded)
+	utils.AssertEqual(t, `[["amount",">=",10]]`, decoded, "encode")
+
+	utils.AssertEqual(t, "", NewFilter().String(), "empty")
+	utils.AssertEqual(t, "", NewFilter().Group(NewFilter()).String(), "empty group")
+
+	_, err = NewFilter().Where("status", "EQUALS", "paid").Build()
+	_, ok := err.(FilterErrors)
+	utils.AssertEqual(t, true, ok, "invalid operator")
+
+	_, err = NewFilter().Where("", "=", "paid").Build()
+	utils.AssertEqual(t, true, nil != err, "empty field")
+
+	_, err = NewFilter().Group(NewFilter().Where("", "=", 1)).Build()
+	utils.AssertEqual(t, true, nil != err, "invalid group")
+}
+
+func TestSortBuilder(t *testing.T) {
+	sort := NewSort().Desc("created_at").Asc("name")
+	utils.AssertEqual(t, "-created_at,name", sort.String(), "sort")
+	utils.AssertEqual(t, "created_at DESC,name ASC", Sorting(sort.String()), "round trip")
+	utils.AssertEqual(t, "-created_at%2Cname", sort.Encode(), "encode")
+
+	_, err := NewSort().Asc("name,id").Build()
+	utils.AssertEqual(t, true, nil != err, "invalid field")
+}
+
+func TestListQueryEncode(t *testing.T) {
+	query := ListQuery{Size: 50, Sort: "-created_at", Filters: `[["status","=","paid"]]`}
+	values, err := url.ParseQuery(query.Encode())
+	utils.AssertEqual(t, nil, err, "parse query")
+	utils.AssertEqual(t, "50", values.Get("size"), "size")
+	utils.AssertEqual(t, "-created_at", values.Get("sort"), "sort")
+	utils.AssertEqual(t, query.Filters, values.Get("filters"), "filters")
+	utils.AssertEqual(t, false, values.Has("page"), "page omitted")
+}
@@ -0,0 +1,94 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// FilterDialect SQL dialect used to compile filters
+type FilterDialect string
+
+// supported filter dialects, same names as the gorm driver names
+const (
+	DialectPostgres FilterDialect = "postgres"
+	DialectMySQL    FilterDialect = "mysql"
+	DialectSQLite   FilterDialect = "sqlite"
+)
+
+// identifierPattern plain column identifier, optionally prefixed by table name
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// DialectOf get filter dialect of gorm database, empty when the driver is not supported
+func DialectOf(db *gorm.DB) FilterDialect {
+	if nil == db || nil == db.Dialector {
+		return ""
+	}
+
+	switch dialect := FilterDialect(db.Dialector.Name()); dialect {
+	case DialectPostgres, DialectMySQL, DialectSQLite:
+		return dialect
+	}
+
+	return ""
+}
+
+// Quote quote column identifier, a name which is not a plain identifier is quoted as a single identifier
+// so it can never become an SQL expression, only QueryField.Column can declare expressions
+//
+//	=> Example
+//	DialectPostgres.Quote("bookings.status")   // "bookings"."status"
+//	DialectMySQL.Quote("bookings.status")      // `bookings`.`status`
+//	DialectPostgres.Quote("name) OR 1=1 --")   // "name) OR 1=1 --"
+func (d FilterDialect) Quote(identifier string) string {
+	if !identifierPattern.MatchString(identifier) {
+		return quoteIdentifier(identifier, d)
+	}
+	if d == "" {
+		return identifier
+	}
+
+	quote := `"`
+	if d == DialectMySQL {
+		quote = "`"
+	}
+
+	segments := strings.Split(identifier, ".")
+	for i, segment := range segments {
+		segments[i] = quote + segment + quote
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// Like case-insensitive LIKE condition of the column
+func (d FilterDialect) Like(column string, not bool) string {
+	operator := "LIKE"
+	if d == DialectPostgres {
+		operator = "ILIKE"
+	}
+	if not {
+		operator = "NOT " + operator
+	}
+
+	switch d {
+	case "", DialectPostgres:
+		return fmt.Sprintf("%s %s ?", column, operator)
+	}
+
+	return fmt.Sprintf("LOWER(%s) %s LOWER(?)", column, operator)
+}
+
+// DateColumn truncate date time column into date
+func (d FilterDialect) DateColumn(column string) string {
+	switch d {
+	case DialectPostgres:
+		return fmt.Sprintf("CAST(%s AS DATE)", column)
+	case DialectMySQL, DialectSQLite:
+		return fmt.Sprintf("DATE(%s)", column)
+	}
+
+	return column
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestFilterDialectQuote(t *testing.T) {
+	utils.AssertEqual(t, `"bookings"."status"`, DialectPostgres.Quote("bookings.status"), "postgres")
+	utils.AssertEqual(t, "`bookings`.`status`", DialectMySQL.Quote("bookings.status"), "mysql")
+	utils.AssertEqual(t, `"status"`, DialectSQLite.Quote("status"), "sqlite")
+	utils.AssertEqual(t, "status", FilterDialect("").Quote("status"), "no dialect")
+	utils.AssertEqual(t, `"LOWER(name)"`, DialectPostgres.Quote("LOWER(name)"), "expressions are quoted as identifier")
+	utils.AssertEqual(t, `"name"" OR 1=1 --"`, FilterDialect("").Quote(`name" OR 1=1 --`), "quotes are escaped")
+	utils.AssertEqual(t, "`a``b`", DialectMySQL.Quote("a`b"), "mysql quotes are escaped")
+}
+
+func TestFilterDialectOf(t *testing.T) {
+	db, _ := dryRunDB(t)
+	utils.AssertEqual(t, FilterDialect(""), DialectOf(db), "unsupported driver")
+	utils.AssertEqual(t, FilterDialect(""), DialectOf(nil), "nil database")
+}
+
+func TestFilterDialectCompile(t *testing.T) {
+	filter := `[["status","LIKE","Paid"],["AND"],["id","IN",["A",1]]]`
+	expr, err := ParseFilter(filter)
+	utils.AssertEqual(t, nil, err, "parse filter")
+
+	query, params := expr.ToDialectSQL(DialectPostgres)
+	utils.AssertEqual(t, `"status" ILIKE ? AND "id" IN ?`, query, "postgres")
+	utils.AssertEqual(t, []interface{}{"%Paid%", []interface{}{"A", "1"}}, params, "postgres params")
+
+	query, _ = expr.ToDialectSQL(DialectMySQL)
+	utils.AssertEqual(t, "LOWER(`status`) LIKE LOWER(?) AND `id` IN ?", query, "mysql")
+
+	query, _ = expr.ToDialectSQL(DialectSQLite)
+	utils.AssertEqual(t, `LOWER("status") LIKE LOWER(?) AND "id" IN ?`, query, "sqlite")
+
+	// typed date fields are compared by date
+	schema := QuerySchema{Dialect: DialectMySQL, Fields: map[string]QueryField{
+		"created_at": {Type: QueryFieldDate, Filterable: true},
+		"amount":     {Type: QueryFieldNumber, Filterable: true, Searchable: true},
+	}}
+	filters, filterParams, search, searchParams, err := schema.CustomFilters(`["created_at","BETWEEN",["2023-01-01","2023-01-31"]]`, "10", "")
+	utils.AssertEqual(t, nil, err, "schema filters")
+	utils.AssertEqual(t, "DATE(`created_at`) BETWEEN ? AND ?", filters, "date filter")
+	utils.AssertEqual(t, []interface{}{"2023-01-01", "2023-01-31"}, filterParams, "date params")
+	utils.AssertEqual(t, "LOWER(`amount`) LIKE LOWER(?)", search, "search")
+	utils.AssertEqual(t, []interface{}{"%10%"}, searchParams, "search params")
+
+	schema.Dialect = DialectPostgres
+	filters, _, _, _, err = schema.CustomFilters(`["created_at","=","2023-01-01"]`, "", "")
+	utils.AssertEqual(t, nil, err, "postgres date filter")
+	utils.AssertEqual(t, `CAST("created_at" AS DATE) = ?`, filters, "postgres date filter")
+
+	// legacy output is unchanged without dialect
+	filters, _, _, _, _ = ParseCustomFilters(`["status","LIKE","Paid"]`, "", "")
+	utils.AssertEqual(t, "status LIKE ?", filters, "legacy")
+}
@@ -0,0 +1,642 @@
+package lib
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+)
+
+// filterMatch result of a condition using SQL three-valued logic
+type filterMatch int8
+
+const (
+	matchFalse filterMatch = iota
+	matchTrue
+	matchUnknown // comparison with NULL
+)
+
+func (m filterMatch) not() filterMatch {
+	switch m {
+	case matchTrue:
+		return matchFalse
+	case matchFalse:
+		return matchTrue
+	}
+	return matchUnknown
+}
+
+func matchOf(ok bool) filterMatch {
+	if ok {
+		return matchTrue
+	}
+	return matchFalse
+}
+
+// FilterSlice apply filters, search and sort of the list query on a slice of maps or structs
+// and paginate the result, items can be a slice or a pointer to slice
+//
+// Fields are resolved by map key or struct JSON tag, field name and its snake case name,
+// nested fields are separated by a dot or double underscore, ex: agent.name, agent__name.
+// Fields unknown to the struct type are rejected like the SQL schema, map keys can not be checked and missing keys are null.
+// Conditions follow the SQL compilation of CustomFilters: comparisons with null never match,
+// LIKE operators and search are case-insensitive, IN strings are lowercased, spaces of LIKE values match any text
+// and sort is parsed by ParseSort with nulls sorted last on ascending order.
+//
+//	=> Example
+//	agents := []model.Agent{}
+//	lib.GetCachingRedis("agents", &agents)
+//	page, err := lib.FilterSlice(agents, lib.GetListQuery(c))
+func FilterSlice(items interface{}, query ListQuery) (Page, error) {
+	query.normalize()
+	page := NewPage(nil, query.Page, query.Size, 0)
+
+	rows := reflect.ValueOf(items)
+	for rows.Kind() == reflect.Ptr {
+		rows = rows.Elem()
+	}
+	if rows.Kind() != reflect.Slice {
+		return page, fmt.Errorf("filter items must be a slice or a pointer to slice, got %T", items)
+	}
+
+	expr, err := ParseFilter(query.Filters)
+	if nil != err {
+		return page, err
+	}
+	columns, err := parseSearchColumns(query.Columns)
+	if nil != err {
+		return page, err
+	}
+	sorts, err := ParseSort(query.Sort)
+	if nil != err {
+		return page, err
+	}
+	if err := checkSliceFields(rows.Type().Elem(), expr, columns, sorts); nil != err {
+		return page, err
+	}
+
+	likes := likePatterns{}
+	matched := []reflect.Value{}
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		if expr.match(row, likes) != matchTrue {
+			continue
+		}
+		if query.Search != "" && len(columns) > 0 && !searchMatch(row, columns, query.Search, likes) {
+			continue
+		}
+		matched = append(matched, row)
+	}
+
+	sortRows(matched, sorts)
+
+	start := query.Page * query.Size
+	end := start + query.Size
+	if start > int64(len(matched)) {
+		start = int64(len(matched))
+	}
+	if end > int64(len(matched)) {
+		end = int64(len(matched))
+	}
+
+	result := reflect.MakeSlice(rows.Type(), 0, int(end-start))
+	for _, row := range matched[start:end] {
+		result = reflect.Append(result, row)
+	}
+
+	return NewPage(result.Interface(), query.Page, query.Size, int64(len(matched))), nil
+}
+
+// Match check whether map or struct item matches the expression, see FilterSlice,
+// fields are not checked and unknown fields are null
+func (f FilterExpr) Match(item interface{}) bool {
+	return f.match(reflect.ValueOf(item), likePatterns{}) == matchTrue
+}
+
+func (f FilterExpr) match(item reflect.Value, likes likePatterns) filterMatch {
+	result := matchTrue
+	switch f.Type {
+	case FilterExprCondition:
+		result = f.Item.match(item, likes)
+	case FilterExprGroup:
+		if f.Logic == "OR" && len(f.Items) > 0 {
+			result = matchFalse
+		}
+		for _, expr := range f.Items {
+			m := expr.match(item, likes)
+			if f.Logic == "OR" {
+				if m == matchTrue || (m == matchUnknown && result == matchFalse) {
+					result = m
+				}
+			} else if m == matchFalse || (m == matchUnknown && result == matchTrue) {
+				result = m
+			}
+		}
+	}
+
+	if f.Not {
+		return result.not()
+	}
+	return result
+}
+
+// match evaluate condition against field value of the item
+//
+//gocyclo:ignore
+func (fi FilterItem) match(item reflect.Value, likes likePatterns) filterMatch {
+	value := lookupFieldValue(item, fi.Field)
+
+	switch fi.Operator {
+	case "IS":
+		return matchOf(nil == value)
+	case "IS NOT":
+		return matchOf(nil != value)
+	case FilterIsEmpty:
+		return matchOf(nil == value || fmt.Sprintf("%v", value) == "")
+	case FilterIsNotEmpty:
+		return matchOf(nil != value && fmt.Sprintf("%v", value) != "")
+	}
+
+	if nil == value {
+		return matchUnknown
+	}
+
+	switch fi.Operator {
+	case "=":
+		return matchOf(equalValues(value, fi.Value))
+	case "!=", "<>":
+		return matchOf(!equalValues(value, fi.Value))
+	case ">", ">=", "<", "<=":
+		c, ok := compareValues(value, fi.Value)
+		if !ok {
+			return matchFalse
+		}
+		switch fi.Operator {
+		case ">":
+			return matchOf(c > 0)
+		case ">=":
+			return matchOf(c >= 0)
+		case "<":
+			return matchOf(c < 0)
+		}
+		return matchOf(c <= 0)
+	case "LIKE", "NOT LIKE", "ILIKE", "NOT ILIKE":
+		// spaces match any text like CreateWhereCause
+		pattern := strings.ReplaceAll(fmt.Sprintf("%%%v%%", fi.Value), " ", "%")
+		return matchOf(likes.match(value, pattern) == !strings.HasPrefix(fi.Operator, "NOT"))
+	case FilterStartsWith:
+		return matchOf(strings.HasPrefix(strings.ToLower(fmt.Sprintf("%v", value)), strings.ToLower(fmt.Sprintf("%v", fi.Value))))
+	case FilterEndsWith:
+		return matchOf(strings.HasSuffix(strings.ToLower(fmt.Sprintf("%v", value)), strings.ToLower(fmt.Sprintf("%v", fi.Value))))
+	case "IN", "NOT IN":
+		values, _ := fi.Value.([]interface{})
+		found := false
+		for _, v := range values {
+			found = found || equalValues(value, inValue(v))
+		}
+		return matchOf(found == (fi.Operator == "IN"))
+	case "BETWEEN":
+		values, _ := fi.Value.([]interface{})
+		if len(values) != 2 {
+			return matchFalse
+		}
+		low, ok1 := compareValues(value, values[0])
+		high, ok2 := compareValues(value, values[1])
+		return matchOf(ok1 && ok2 && low >= 0 && high <= 0)
+	case FilterDateBetween:
+		values, _ := fi.Value.([]interface{})
+		from, to, err := filterDateRange(values)
+		t, ok := timeValue(value)
+		return matchOf(nil == err && ok && !t.Before(from) && t.Before(to))
+	case FilterContains:
+		// json columns are often stored as text
+		if document, isString := value.(string); isString {
+			JSONUnmarshal([]byte(document), &value)
+		}
+		return matchOf(containsValue(normalizeValue(value), normalizeValue(fi.Value)))
+	}
+
+	return matchFalse
+}
+
+// searchMatch check whether any search column contains the term
+func searchMatch(item reflect.Value, columns []string, term string, likes likePatterns) bool {
+	for _, column := range columns {
+		value := lookupFieldValue(item, column)
+		if nil != value && likes.match(value, "%"+term+"%") {
+			return true
+		}
+	}
+	return false
+}
+
+// sortRows sort rows by sort items of ParseSort, nulls are sorted like postgres unless the item sets Nulls
+func sortRows(rows []reflect.Value, items SortItems) {
+	if len(items) == 0 {
+		return
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, item := range items {
+			a := lookupFieldValue(rows[i], item.Field)
+			b := lookupFieldValue(rows[j], item.Field)
+			nullsFirst := item.Nulls == SortNullsFirst || (item.Nulls == "" && item.Desc)
+			switch {
+			case nil == a && nil == b:
+				continue
+			case nil == a:
+				return nullsFirst
+			case nil == b:
+				return !nullsFirst
+			}
+
+			c, ok := compareValues(a, b)
+			if !ok {
+				c = strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b))
+			}
+			if c != 0 {
+				return (c < 0) != item.Desc
+			}
+		}
+		return false
+	})
+}
+
+// checkSliceFields reject filter, search and sort fields unknown to the element type
+func checkSliceFields(element reflect.Type, expr FilterExpr, columns []string, sorts SortItems) error {
+	errs := FilterErrors{}
+	var checkExpr func(expr FilterExpr)
+	checkExpr = func(expr FilterExpr) {
+		if expr.Type == FilterExprCondition {
+			if !knownField(element, expr.Item.Field) {
+				errs = append(errs, FilterError{Value: expr.Item.Field, Message: fmt.Sprintf("unknown field %s", expr.Item.Field)})
+			}
+			return
+		}
+		for _, item := range expr.Items {
+			checkExpr(item)
+		}
+	}
+	checkExpr(expr)
+
+	for i, column := range columns {
+		if !knownField(element, column) {
+			errs = append(errs, FilterError{Name: searchColumnParamName, Position: []int{i}, Value: column, Message: fmt.Sprintf("unknown field %s", column)})
+		}
+	}
+	for i, item := range sorts {
+		if !knownField(element, item.Field) {
+			errs = append(errs, FilterError{Name: sortParamName, Position: []int{i}, Value: item.Field, Message: fmt.Sprintf("unknown field %s", item.Field)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// knownField check whether field path exists on the type, fields below maps and interfaces are always known
+func knownField(t reflect.Type, field string) bool {
+	field = strings.Trim(field, "\"`")
+	for _, path := range strings.Split(field, ".") {
+		for _, name := range strings.Split(path, "__") {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			switch t.Kind() {
+			case reflect.Map, reflect.Interface:
+				return true
+			case reflect.Struct:
+				f, ok := lookupStructField(t, name)
+				if !ok {
+					return false
+				}
+				t = f.Type
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// lookupFieldValue get normalized field value of map or struct item, nil when field is not found
+func lookupFieldValue(item reflect.Value, field string) interface{} {
+	item = lookupFieldPath(item, field)
+	if !item.IsValid() {
+		return nil
+	}
+	return normalizeValue(item.Interface())
+}
+
+// lookupFieldPath field of dotted or double underscore path, invalid when the field or its parent is not found
+func lookupFieldPath(item reflect.Value, field string) reflect.Value {
+	field = strings.Trim(field, "\"`")
+	for _, path := range strings.Split(field, ".") {
+		for _, name := range strings.Split(path, "__") {
+			item = lookupField(item, name)
+			if !item.IsValid() {
+				return item
+			}
+		}
+	}
+	return item
+}
+
+func lookupField(item reflect.Value, name string) reflect.Value {
+	for item.IsValid() && (item.Kind() == reflect.Ptr || item.Kind() == reflect.Interface) {
+		if item.IsNil() {
+			return reflect.Value{}
+		}
+		item = item.Elem()
+	}
+
+	switch item.Kind() {
+	case reflect.Map:
+		if item.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}
+		}
+		if value := item.MapIndex(reflect.ValueOf(name).Convert(item.Type().Key())); value.IsValid() {
+			return value
+		}
+		iter := item.MapRange()
+		for iter.Next() {
+			if strings.EqualFold(iter.Key().String(), name) {
+				return iter.Value()
+			}
+		}
+	case reflect.Struct:
+		if f, ok := lookupStructField(item.Type(), name); ok {
+			value, err := item.FieldByIndexErr(f.Index)
+			if nil == err {
+				return value
+			}
+		}
+	}
+
+	return reflect.Value{}
+}
+
+// lookupStructField field of struct type by JSON tag, field name or its snake case name, embedded structs are searched last
+func lookupStructField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == name || strings.EqualFold(f.Name, name) || strings.EqualFold(strcase.ToSnake(f.Name), name) {
+			return f, true
+		}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		embedded := f.Type
+		if embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+		if !f.Anonymous || embedded.Kind() != reflect.Struct {
+			continue
+		}
+		if found, ok := lookupStructField(embedded, name); ok {
+			found.Index = append([]int{i}, found.Index...)
+			return found, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// normalizeValue convert value into nil, float64, bool, string, time.Time, []interface{} or map[string]interface{}
+//
+//gocyclo:ignore
+func normalizeValue(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	value = v.Interface()
+	if t, ok := value.(time.Time); ok {
+		return t
+	}
+	if valuer, ok := value.(driver.Valuer); ok {
+		dbValue, err := valuer.Value()
+		if nil != err {
+			return nil
+		}
+		if _, isValuer := dbValue.(driver.Valuer); isValuer {
+			return dbValue
+		}
+		return normalizeValue(dbValue)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.Kind() == reflect.Slice {
+			return string(v.Bytes())
+		}
+		values := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, normalizeValue(v.Index(i).Interface()))
+		}
+		return values
+	case reflect.Map:
+		values := map[string]interface{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			values[fmt.Sprintf("%v", iter.Key().Interface())] = normalizeValue(iter.Value().Interface())
+		}
+		return values
+	}
+
+	return value
+}
+
+// compareValues compare item value with filter value, ok is false when values are not comparable
+func compareValues(a, b interface{}) (int, bool) {
+	a, b = normalizeValue(a), normalizeValue(b)
+	if nil == a || nil == b {
+		return 0, false
+	}
+
+	switch x := a.(type) {
+	case float64:
+		if y, ok := numberValue(b); ok {
+			return compareNumbers(x, y), true
+		}
+	case time.Time:
+		if y, ok := timeValue(b); ok {
+			return x.Compare(y), true
+		}
+	case bool:
+		if y, ok := boolValue(b); ok {
+			return strings.Compare(strconv.FormatBool(x), strconv.FormatBool(y)), true
+		}
+	case string:
+		switch y := b.(type) {
+		case float64:
+			if n, ok := numberValue(x); ok {
+				return compareNumbers(n, y), true
+			}
+		case time.Time:
+			if t, ok := timeValue(x); ok {
+				return t.Compare(y), true
+			}
+		case bool:
+			if v, ok := boolValue(x); ok {
+				return strings.Compare(strconv.FormatBool(v), strconv.FormatBool(y)), true
+			}
+		case string:
+			return strings.Compare(x, y), true
+		}
+	}
+
+	return strings.Compare(fmt.Sprintf("%v", a), fmt.Sprintf("%v", b)), true
+}
+
+func equalValues(a, b interface{}) bool {
+	c, ok := compareValues(a, b)
+	return ok && c == 0
+}
+
+// inValue IN value as it is bound by CreateWhereCause, strings are lowercased while the column is compared as is
+func inValue(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		return strings.ToLower(s)
+	}
+	return value
+}
+
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, nil == err
+	}
+	return 0, false
+}
+
+func boolValue(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, nil == err
+	}
+	return false, false
+}
+
+func timeValue(value interface{}) (time.Time, bool) {
+	switch v := normalizeValue(value).(type) {
+	case time.Time:
+		return v, true
+	case string:
+		t, err := QueryField{Type: QueryFieldDateTime}.Coerce(v)
+		if nil == err {
+			return t.(time.Time), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// likePatterns compiled LIKE patterns, every pattern is compiled once per FilterSlice
+type likePatterns map[string]*regexp.Regexp
+
+// match case-insensitive SQL LIKE pattern matching, % matches any text and _ matches a single character
+func (l likePatterns) match(value interface{}, pattern string) bool {
+	expression, ok := l[pattern]
+	if !ok {
+		expression = likeExpression(pattern)
+		l[pattern] = expression
+	}
+	return expression.MatchString(fmt.Sprintf("%v", value))
+}
+
+// likeExpression regular expression of LIKE pattern
+func likeExpression(pattern string) *regexp.Regexp {
+	expression := strings.Builder{}
+	expression.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			expression.WriteString(".*")
+		case '_':
+			expression.WriteString(".")
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expression.WriteString("$")
+
+	return regexp.MustCompile(expression.String())
+}
+
+// containsValue check whether value contains all elements of an array or all keys of an object like postgres @>
+func containsValue(value, wanted interface{}) bool {
+	switch w := wanted.(type) {
+	case []interface{}:
+		values, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, element := range w {
+			found := false
+			for _, v := range values {
+				found = found || containsValue(v, element)
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, element := range w {
+			if v, exists := values[key]; !exists || !containsValue(v, element) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return equalValues(value, wanted)
+}
@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+type memoryAgent struct {
+	Name string `json:"name"`
+}
+
+type memorySample struct {
+	ID        int64        `json:"id"`
+	Status    string       `json:"status"`
+	Amount    *float64     `json:"amount"`
+	Tags      []string     `json:"tags"`
+	CreatedAt time.Time    `json:"created_at"`
+	Agent     *memoryAgent `json:"agent"`
+}
+
+func memorySamples() []memorySample {
+	amount := func(v float64) *float64 { return &v }
+	return []memorySample{
+		{ID: 1, Status: "paid", Amount: amount(100), Tags: []string{"vip", "b2b"}, CreatedAt: time.Date(2023, 1, 1, 20, 0, 0, 0, time.UTC), Agent: &memoryAgent{Name: "John"}},
+		{ID: 2, Status: "Due", Amount: amount(50), Tags: []string{"b2c"}, CreatedAt: time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{ID: 3, Status: "paid", Amount: nil, CreatedAt: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), Agent: &memoryAgent{Name: "Jane"}},
+		{ID: 4, Status: "cancelled", Amount: amount(75), Tags: []string{"vip"}, CreatedAt: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+func memoryIDs(page Page) []int64 {
+	ids := []int64{}
+	for _, item := range page.Items.([]memorySample) {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestFilterSlice(t *testing.T) {
+	items := memorySamples()
+	cases := []struct {
+		query ListQuery
+		ids   []int64
+		total int64
+	}{
+		{ListQuery{}, []int64{1, 2, 3, 4}, 4},
+		{ListQuery{Filters: `["status","=","paid"]`}, []int64{1, 3}, 2},
+		{ListQuery{Filters: `["amount",">=","75"]`}, []int64{1, 4}, 2},
+		// comparisons with null never match, even when negated
+		{ListQuery{Filters: `["NOT",["amount",">",60]]`}, []int64{2}, 1},
+		{ListQuery{Filters: `["amount","IS",null]`}, []int64{3}, 1},
+		{ListQuery{Filters: `[["status","IN",["paid","cancelled"]],["AND"],[["amount","<",80],["OR"],["agent__name","LIKE","jan"]]]`}, []int64{3, 4}, 2},
+		{ListQuery{Filters: `["status","STARTS WITH","du"]`}, []int64{2}, 1},
+		// wildcards are literal like the escaped SQL pattern
+		{ListQuery{Filters: `["status","STARTS WITH","p_"]`}, []int64{}, 0},
+		{ListQuery{Filters: `["status","ENDS WITH","%d"]`}, []int64{}, 0},
+		{ListQuery{Filters: `["tags","@>",["vip"]]`}, []int64{1, 4}, 2},
+		{ListQuery{Filters: `["created_at","DATE BETWEEN",["2023-01-02","2023-01-31","+07:00"]]`}, []int64{1, 2}, 2},
+		{ListQuery{Filters: `["id","BETWEEN",[2,3]]`}, []int64{2, 3}, 2},
+		{ListQuery{Search: "JO", Columns: `["agent.name","status"]`}, []int64{1}, 1},
+		{ListQuery{Sort: "-amount"}, []int64{3, 1, 4, 2}, 4},
+		{ListQuery{Sort: "status,-id"}, []int64{2, 4, 3, 1}, 4},
+		{ListQuery{Sort: "id", Page: 1, Size: 3}, []int64{4}, 4},
+		{ListQuery{Sort: "amount:nulls_first"}, []int64{3, 2, 4, 1}, 4},
+		{ListQuery{Sort: "-agent__name:nulls_last,id"}, []int64{1, 3, 2, 4}, 4},
+		// IN strings are lowercased and spaces of LIKE match any text like the SQL filters
+		{ListQuery{Filters: `["status","IN",["DUE","Cancelled"]]`}, []int64{4}, 1},
+		{ListQuery{Filters: `["status","LIKE","can led"]`}, []int64{4}, 1},
+		{ListQuery{Filters: `["status","NOT ILIKE","PAID"]`}, []int64{2, 4}, 2},
+	}
+
+	for _, c := range cases {
+		page, err := FilterSlice(&items, c.query)
+		utils.AssertEqual(t, nil, err, c.query.Filters)
+		utils.AssertEqual(t, c.ids, memoryIDs(page), c.query.Filters+c.query.Sort+c.query.Search)
+		utils.AssertEqual(t, c.total, page.Total, c.query.Filters)
+		utils.AssertEqual(t, int64(len(c.ids)), page.Visible, c.query.Filters)
+	}
+
+	_, err := FilterSlice(items, ListQuery{Filters: `["status","EQUALS","paid"]`})
+	_, ok := err.(FilterErrors)
+	utils.AssertEqual(t, true, ok, "invalid filters")
+
+	_, err = FilterSlice(memorySample{}, ListQuery{})
+	utils.AssertEqual(t, true, nil != err, "not a slice")
+
+	_, err = FilterSlice(items, ListQuery{Filters: `["stauts","IS",null]`})
+	utils.AssertEqual(t, "invalid filters: filters: unknown field stauts", err.Error(), "unknown filter field")
+	_, err = FilterSlice(items, ListQuery{Sort: "agent__email"})
+	utils.AssertEqual(t, "invalid filters: sort[0]: unknown field agent__email", err.Error(), "unknown sort field")
+	_, err = FilterSlice(items, ListQuery{Search: "a", Columns: `["status","tags.name"]`})
+	utils.AssertEqual(t, "invalid filters: columns[1]: unknown field tags.name", err.Error(), "unknown search column")
+	_, err = FilterSlice(items, ListQuery{Sort: "-id:nulls"})
+	_, ok = err.(FilterErrors)
+	utils.AssertEqual(t, true, ok, "invalid sort")
+}
+
+func TestFilterSliceMaps(t *testing.T) {
+	items := []map[string]interface{}{
+		{"id": 1, "name": "Bali", "meta": `{"country":"ID","tags":["beach"]}`},
+		{"id": 2, "name": "Tokyo", "meta": map[string]interface{}{"country": "JP"}},
+		{"id": 3, "name": nil},
+	}
+
+	page, err := FilterSlice(items, ListQuery{Filters: `[["name","LIKE","o"],["OR"],["meta","@>",{"tags":["beach"]}]]`, Sort: "-id"})
+	utils.AssertEqual(t, nil, err, "filter maps")
+	utils.AssertEqual(t, 2, len(page.Items.([]map[string]interface{})), "matched maps")
+	utils.AssertEqual(t, 2, page.Items.([]map[string]interface{})[0]["id"], "sorted maps")
+
+	page, _ = FilterSlice(items, ListQuery{Filters: `["name","IS EMPTY",null]`})
+	utils.AssertEqual(t, int64(1), page.Total, "is empty")
+}
+
+func TestFilterSliceMatchesSQL(t *testing.T) {
+	items := memorySamples()
+	for _, filter := range []string{
+		`["status","IN",["PAID","due"]]`,
+		`["status","NOT IN",["Due","cancelled"]]`,
+		`["id","IN",[1,"3"]]`,
+	} {
+		// evaluate the bound parameters of the SQL filter the way the database compares them
+		query, params, _, _ := CustomFilters(filter, "", "")
+		column := strings.Fields(query)[0]
+		values := map[string]bool{}
+		for _, param := range params[0].([]interface{}) {
+			values[param.(string)] = true
+		}
+		expected := []int64{}
+		for _, item := range items {
+			value := fmt.Sprintf("%v", lookupFieldValue(reflect.ValueOf(item), column))
+			if values[value] != strings.Contains(query, "NOT IN") {
+				expected = append(expected, item.ID)
+			}
+		}
+
+		page, err := FilterSlice(items, ListQuery{Filters: filter})
+		utils.AssertEqual(t, nil, err, filter)
+		utils.AssertEqual(t, expected, memoryIDs(page), filter)
+	}
+}
@@ -0,0 +1,669 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// FilterExprCondition filter expression holding a single condition
+	FilterExprCondition = "condition"
+	// FilterExprGroup filter expression holding nested expressions joined by a logic operator
+	FilterExprGroup = "group"
+
+	filterMaxDepth        = 10 // maximum nesting level of filter groups
+	filterParamName       = "filters"
+	searchColumnParamName = "columns"
+)
+
+// filterOperators supported filter operators
+var filterOperators map[string]bool = map[string]bool{
+	"=":         true,
+	"!=":        true,
+	"<>":        true,
+	">":         true,
+	">=":        true,
+	"<":         true,
+	"<=":        true,
+	"LIKE":      true,
+	"NOT LIKE":  true,
+	"ILIKE":     true, // case-insensitive on every dialect, LOWER(column) LIKE LOWER(?) without dialect
+	"NOT ILIKE": true,
+	"IN":        true,
+	"NOT IN":    true,
+	"BETWEEN":   true,
+	"IS":        true,
+	"IS NOT":    true,
+
+	FilterStartsWith:  true,
+	FilterEndsWith:    true,
+	FilterContains:    true,
+	FilterIsEmpty:     true,
+	FilterIsNotEmpty:  true,
+	FilterDateBetween: true,
+}
+
+// FilterExpr typed filter expression tree
+//
+//	condition -> Type: "condition", Item: field, operator and value
+//	group     -> Type: "group", Logic: "AND" / "OR", Items: nested expressions
+//	Not       -> negate the condition or group
+type FilterExpr struct {
+	Type  string       `json:"type"`
+	Logic string       `json:"logic,omitempty"`
+	Not   bool         `json:"not,omitempty"`
+	Item  FilterItem   `json:"item"`
+	Items []FilterExpr `json:"items,omitempty"`
+}
+
+// IsEmpty check whether expression contains any condition
+func (f FilterExpr) IsEmpty() bool {
+	if f.Type == FilterExprCondition {
+		return false
+	}
+	for _, item := range f.Items {
+		if !item.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// ToSQL compile expression into where clause and its bind parameters
+func (f FilterExpr) ToSQL() (string, []interface{}) {
+	query, params, _ := filterCompiler{}.compile(f)
+	return query, params
+}
+
+// ToDialectSQL compile expression into where clause of the given SQL dialect
+func (f FilterExpr) ToDialectSQL(dialect FilterDialect) (string, []interface{}) {
+	query, params, _ := filterCompiler{dialect: dialect}.compile(f)
+	return query, params
+}
+
+// FilterError filter element error
+type FilterError struct {
+	Name     string      `json:"name,omitempty"` // query parameter name, default `filters`
+	Position []int       `json:"position"`       // element position, ex: [2, 1] is the second element of the third condition
+	Value    interface{} `json:"value,omitempty"`
+	Message  string      `json:"message"`
+}
+
+// Path filter element path, ex: filters[2][1]
+func (e FilterError) Path() string {
+	path := e.param()
+	for _, p := range e.Position {
+		path += fmt.Sprintf("[%d]", p)
+	}
+	return path
+}
+
+func (e FilterError) param() string {
+	if e.Name == "" {
+		return filterParamName
+	}
+	return e.Name
+}
+
+// Error implement error interface
+func (e FilterError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path(), e.Message)
+}
+
+// FilterErrors list of filter element errors
+type FilterErrors []FilterError
+
+// Error implement error interface
+func (e FilterErrors) Error() string {
+	messages := []string{}
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return "invalid filters: " + strings.Join(messages, "; ")
+}
+
+// ErrorData convert filter errors into response error details
+func (e FilterErrors) ErrorData() []ErrorData {
+	errorDetails := []ErrorData{}
+	for _, err := range e {
+		errorDetails = append(errorDetails, ErrorData{
+			Name:      err.param(),
+			Path:      err.Path(),
+			Value:     err.Value,
+			Validator: "filter",
+			Message:   err.Message,
+		})
+	}
+	return errorDetails
+}
+
+// ParseFilter parse filter DSL into expression tree
+//
+//	single   -> ["id","=",1]
+//	multiple -> [["id","=",1],["AND"],["status","=","active"]]
+//	group    -> [["status","IN",["x","y"]],["AND"],[["agent","=","A"],["OR"],["corporate","=","B"]]]
+//	negation -> ["NOT",["status","=","x"]] or [["id","=",1],["AND"],["NOT",[["a","=",1],["OR"],["b","=",2]]]]
+//
+// adjacent conditions without operator are joined with OR,
+// AND takes precedence over OR, use group to change the precedence
+func ParseFilter(jsonParams string) (FilterExpr, error) {
+	return parseFilter(jsonParams, nil)
+}
+
+func parseFilter(jsonParams string, schema *QuerySchema) (FilterExpr, error) {
+	result := FilterExpr{Type: FilterExprGroup, Logic: "AND"}
+	if strings.TrimSpace(jsonParams) == "" {
+		return result, nil
+	}
+
+	var output interface{}
+	if err := JSONUnmarshal([]byte(jsonParams), &output); nil != err {
+		return result, FilterErrors{{Value: jsonParams, Message: "filters must be a valid JSON array"}}
+	}
+
+	elements, ok := output.([]interface{})
+	if !ok {
+		return result, FilterErrors{{Value: output, Message: "filters must be a JSON array"}}
+	}
+
+	p := filterParser{schema: schema}
+	if len(elements) > 0 {
+		if expr, valid := p.expr(elements, []int{}, 0); valid {
+			result = simplifyFilterExpr(FilterExpr{Type: FilterExprGroup, Logic: "AND", Items: []FilterExpr{expr}})
+		}
+	}
+
+	if len(p.errors) > 0 {
+		return result, p.errors
+	}
+
+	return result, nil
+}
+
+type filterParser struct {
+	schema *QuerySchema
+	errors FilterErrors
+}
+
+func (p *filterParser) fail(position []int, value interface{}, message string, args ...interface{}) {
+	p.errors = append(p.errors, FilterError{
+		Position: append([]int{}, position...),
+		Value:    value,
+		Message:  fmt.Sprintf(message, args...),
+	})
+}
+
+// expr parse group, negation or single condition
+func (p *filterParser) expr(item []interface{}, position []int, depth int) (FilterExpr, bool) {
+	if depth > filterMaxDepth {
+		p.fail(position, nil, "filter groups can not be nested more than %d levels", filterMaxDepth)
+		return FilterExpr{}, false
+	}
+
+	if isFilterNegation(item) {
+		operand, _ := item[1].([]interface{})
+		expr, valid := p.expr(operand, append(append([]int{}, position...), 1), depth+1)
+		expr.Not = !expr.Not
+		return expr, valid
+	}
+
+	if len(item) > 0 {
+		if _, isList := item[0].([]interface{}); isList {
+			errorCount := len(p.errors)
+			expr := p.list(item, position, depth)
+			return expr, len(p.errors) == errorCount
+		}
+	}
+
+	condition, valid := p.condition(item, position)
+	return FilterExpr{Type: FilterExprCondition, Item: condition}, valid
+}
+
+// isFilterNegation check negation element, ex: ["NOT",["id","=",1]]
+func isFilterNegation(item []interface{}) bool {
+	if len(item) != 2 {
+		return false
+	}
+	keyword, _ := item[0].(string)
+	_, isList := item[1].([]interface{})
+	return isList && strings.ToUpper(strings.TrimSpace(keyword)) == "NOT"
+}
+
+// list parse list of conditions, groups and logic operators into OR of AND groups
+func (p *filterParser) list(elements []interface{}, position []int, depth int) FilterExpr {
+	orGroup := FilterExpr{Type: FilterExprGroup, Logic: "OR"}
+	andGroup := FilterExpr{Type: FilterExprGroup, Logic: "AND"}
+	expectOperand := true
+	lastOperator := -1
+
+	for i, element := range elements {
+		pos := append(append([]int{}, position...), i)
+		item, ok := element.([]interface{})
+		if !ok {
+			p.fail(pos, element, "element must be a condition or logic operator array")
+			continue
+		}
+		if len(item) == 0 {
+			p.fail(pos, element, "element must not be empty")
+			expectOperand = false // reported once, the following logic operator is still valid
+			continue
+		}
+
+		if logic, isLogic := item[0].(string); len(item) == 1 && isLogic {
+			logic = strings.ToUpper(strings.TrimSpace(logic))
+			if logic != "AND" && logic != "OR" {
+				p.fail(pos, item[0], "logic operator must be AND or OR")
+				continue
+			}
+			if expectOperand {
+				p.fail(pos, item[0], "logic operator %s must be placed between conditions", logic)
+				continue
+			}
+			if logic == "OR" {
+				orGroup.Items = append(orGroup.Items, andGroup)
+				andGroup = FilterExpr{Type: FilterExprGroup, Logic: "AND"}
+			}
+			expectOperand = true
+			lastOperator = i
+			continue
+		}
+
+		// adjacent conditions without logic operator are joined with OR
+		if !expectOperand {
+			orGroup.Items = append(orGroup.Items, andGroup)
+			andGroup = FilterExpr{Type: FilterExprGroup, Logic: "AND"}
+		}
+		expectOperand = false
+
+		if expr, valid := p.expr(item, pos, depth+1); valid {
+			andGroup.Items = append(andGroup.Items, expr)
+		}
+	}
+
+	if expectOperand && lastOperator >= 0 {
+		p.fail(append(append([]int{}, position...), lastOperator), elements[lastOperator], "logic operator must be followed by a condition")
+	}
+
+	orGroup.Items = append(orGroup.Items, andGroup)
+
+	return simplifyFilterExpr(orGroup)
+}
+
+// condition parse single condition, ex: ["field","operator",value] or ["field",value]
+//
+//gocyclo:ignore
+func (p *filterParser) condition(item []interface{}, position []int) (FilterItem, bool) {
+	errorCount := len(p.errors)
+	result := FilterItem{Operator: "="}
+	if len(item) != 2 && len(item) != 3 {
+		p.fail(position, item, "condition must contain 2 or 3 elements, got %d", len(item))
+		return result, false
+	}
+
+	field, ok := item[0].(string)
+	if !ok || strings.TrimSpace(field) == "" {
+		p.fail(append(position, 0), item[0], "field name must be a non-empty string")
+	}
+	field = strings.TrimSpace(field)
+	// without schema the field becomes SQL text, only plain identifiers like agent__name or bookings.status are allowed
+	if nil == p.schema && field != "" && !identifierPattern.MatchString(strings.ReplaceAll(field, "\"", "")) {
+		p.fail(append(position, 0), item[0], "invalid field name %s", field)
+	}
+
+	value := item[1]
+	if len(item) == 3 {
+		operator, ok := item[1].(string)
+		operator = strings.ToUpper(strings.Join(strings.Fields(operator), " "))
+		if !ok || !filterOperators[operator] {
+			p.fail(append(position, 1), item[1], "unsupported operator %v", item[1])
+		}
+		result.Operator = operator
+		value = item[2]
+	}
+	valuePosition := append(append([]int{}, position...), len(item)-1)
+
+	switch v := value.(type) {
+	case nil:
+		result.ValueType = "null"
+	case string, bool, float64:
+		SetFilterValue(&result, v)
+	case []interface{}:
+		for i, val := range v {
+			switch val.(type) {
+			case string, bool, float64:
+			default:
+				p.fail(append(valuePosition, i), val, "array value must contain only strings, numbers or booleans")
+			}
+		}
+		SetFilterValue(&result, v)
+	case map[string]interface{}:
+		if result.Operator != FilterContains {
+			p.fail(valuePosition, value, "object value is only allowed on operator @>")
+		}
+		SetFilterValue(&result, v)
+	default:
+		p.fail(valuePosition, value, "unsupported value type %T", value)
+	}
+
+	switch result.Operator {
+	case "IS", "IS NOT", FilterIsEmpty, FilterIsNotEmpty:
+		if s, isString := result.Value.(string); isString && strings.ToUpper(s) == "NULL" {
+			result.Value = nil
+			result.ValueType = "null"
+		}
+		if result.ValueType != "null" {
+			p.fail(valuePosition, value, "operator %s only accepts null value", result.Operator)
+		}
+	case "IN", "NOT IN":
+		if values, isArray := result.Value.([]interface{}); !isArray || len(values) == 0 {
+			p.fail(valuePosition, value, "operator %s requires a non-empty array value", result.Operator)
+		}
+	case "BETWEEN":
+		values, isArray := result.Value.([]interface{})
+		if !isArray || len(values) != 2 {
+			p.fail(valuePosition, value, "operator BETWEEN requires an array of 2 values")
+			break
+		}
+		for i, val := range values {
+			switch val.(type) {
+			case string, float64:
+				if reflect.TypeOf(val) != reflect.TypeOf(values[0]) {
+					p.fail(append(valuePosition, i), val, "operator BETWEEN requires values of the same type")
+				}
+			default:
+				p.fail(append(valuePosition, i), val, "operator BETWEEN requires string or number values")
+			}
+		}
+	case FilterDateBetween:
+		values, _ := result.Value.([]interface{})
+		if _, _, err := filterDateRange(values); nil != err {
+			p.fail(valuePosition, value, "operator DATE BETWEEN %s", err.Error())
+		}
+	case FilterContains:
+		if values, isArray := result.Value.([]interface{}); (!isArray || len(values) == 0) && result.ValueType != "object" {
+			p.fail(valuePosition, value, "operator @> requires a non-empty array or an object")
+		}
+	case FilterStartsWith, FilterEndsWith:
+		if result.ValueType != "string" && result.ValueType != "float64" {
+			p.fail(valuePosition, value, "operator %s requires a string or number value", result.Operator)
+		}
+	default:
+		if result.ValueType == "array" || result.ValueType == "null" {
+			p.fail(valuePosition, value, "operator %s requires a string, number or boolean value", result.Operator)
+		}
+	}
+
+	if len(p.errors) > errorCount {
+		return result, false
+	}
+
+	if nil != p.schema {
+		p.schema.resolve(p, &result, field, position, valuePosition)
+	} else {
+		result.Field = strings.ReplaceAll(NormalizeFieldName(field), "\"", "")
+	}
+
+	return result, len(p.errors) == errorCount
+}
+
+// simplifyFilterExpr remove empty groups and unwrap groups containing a single expression
+func simplifyFilterExpr(expr FilterExpr) FilterExpr {
+	if expr.Type != FilterExprGroup {
+		return expr
+	}
+
+	items := []FilterExpr{}
+	for _, item := range expr.Items {
+		item = simplifyFilterExpr(item)
+		if item.IsEmpty() {
+			continue
+		}
+		// flatten nested group with the same logic operator
+		if item.Type == FilterExprGroup && item.Logic == expr.Logic && !item.Not {
+			items = append(items, item.Items...)
+			continue
+		}
+		items = append(items, item)
+	}
+	expr.Items = items
+
+	if len(items) == 1 && items[0].Type == FilterExprGroup {
+		item := items[0]
+		item.Not = item.Not != expr.Not
+		return item
+	}
+
+	return expr
+}
+
+// filterCompiler compile filter expression into SQL where clause
+//
+//	typed   -> fields are SQL columns resolved by QuerySchema and bind parameters keep their coerced type
+//	           instead of legacy CreateWhereCause formatting, other fields are quoted by the dialect
+//	dialect -> quote identifiers and use case-insensitive matching of the SQL dialect
+type filterCompiler struct {
+	typed   bool
+	dialect FilterDialect
+}
+
+func (fc filterCompiler) compile(expr FilterExpr) (string, []interface{}, error) {
+	queryFilters := []string{}
+	whereParams := []interface{}{}
+	if err := fc.compileExpr(expr, "", &queryFilters, &whereParams); nil != err {
+		return "", []interface{}{}, err
+	}
+
+	return strings.Join(queryFilters, " "), whereParams, nil
+}
+
+func (fc filterCompiler) compileExpr(expr FilterExpr, parentLogic string, queryFilters *[]string, whereParams *[]interface{}) error {
+	if expr.Type == FilterExprCondition {
+		if !expr.Not {
+			return fc.compileCondition(expr.Item, queryFilters, whereParams)
+		}
+		parts := []string{}
+		if err := fc.compileCondition(expr.Item, &parts, whereParams); nil != err {
+			return err
+		}
+		*queryFilters = append(*queryFilters, "NOT ("+strings.Join(parts, " ")+")")
+		return nil
+	}
+
+	parts := []string{}
+	for _, item := range expr.Items {
+		if item.IsEmpty() {
+			continue
+		}
+		if len(parts) > 0 {
+			parts = append(parts, expr.Logic)
+		}
+		if err := fc.compileExpr(item, expr.Logic, &parts, whereParams); nil != err {
+			return err
+		}
+	}
+
+	if len(parts) == 0 {
+		return nil
+	}
+
+	query := strings.Join(parts, " ")
+	// AND takes precedence over OR, so only OR groups nested inside AND need parentheses
+	if expr.Not {
+		query = "NOT (" + query + ")"
+	} else if parentLogic == "AND" && expr.Logic == "OR" && len(expr.Items) > 1 {
+		query = "(" + query + ")"
+	}
+	*queryFilters = append(*queryFilters, query)
+
+	return nil
+}
+
+func (fc filterCompiler) compileCondition(item FilterItem, queryFilters *[]string, whereParams *[]interface{}) error {
+	if item.ValueType == "null" {
+		item.Value = nil
+	}
+	column := item.Field
+	if !fc.typed {
+		column = fc.dialect.Quote(item.Field)
+	}
+	if isExtendedOperator(item.Operator) {
+		cause, params, err := extendedWhereCause(item, column, fc.dialect)
+		if nil != err {
+			return err
+		}
+		*queryFilters = append(*queryFilters, cause)
+		*whereParams = append(*whereParams, params...)
+		return nil
+	}
+	if !fc.typed && fc.dialect == "" {
+		CreateWhereCause(QueryFilter{Item: item, Type: "multiple"}, queryFilters, whereParams)
+		return nil
+	}
+
+	if item.ValueType == QueryFieldDate {
+		column = fc.dialect.DateColumn(column)
+	}
+
+	switch item.Operator {
+	case "IS", "IS NOT":
+		*queryFilters = append(*queryFilters, fmt.Sprintf("(%s %s NULL)", column, item.Operator))
+	case "LIKE", "NOT LIKE", "ILIKE", "NOT ILIKE":
+		if fc.dialect == "" && strings.HasSuffix(item.Operator, "ILIKE") {
+			*queryFilters = append(*queryFilters, lowerLike(column, item.Operator))
+		} else {
+			*queryFilters = append(*queryFilters, fc.dialect.Like(column, strings.HasPrefix(item.Operator, "NOT")))
+		}
+		*whereParams = append(*whereParams, fmt.Sprintf("%%%v%%", item.Value))
+	case "IN", "NOT IN":
+		values, _ := item.Value.([]interface{})
+		params := []interface{}{}
+		for _, value := range values {
+			params = append(params, fc.param(item, value))
+		}
+		*queryFilters = append(*queryFilters, fmt.Sprintf("%s %s ?", column, item.Operator))
+		*whereParams = append(*whereParams, params)
+	case "BETWEEN":
+		values, _ := item.Value.([]interface{})
+		if len(values) != 2 {
+			return fmt.Errorf("operator BETWEEN on %s requires 2 values", item.Field)
+		}
+		*queryFilters = append(*queryFilters, fmt.Sprintf("%s BETWEEN ? AND ?", column))
+		*whereParams = append(*whereParams, fc.param(item, values[0]), fc.param(item, values[1]))
+	default:
+		*queryFilters = append(*queryFilters, fmt.Sprintf("%s %s ?", column, item.Operator))
+		*whereParams = append(*whereParams, fc.param(item, item.Value))
+	}
+
+	return nil
+}
+
+// param format bind parameter, untyped values are formatted like CreateWhereCause
+func (fc filterCompiler) param(item FilterItem, value interface{}) interface{} {
+	if !fc.typed {
+		return fmt.Sprintf("%v", value)
+	}
+	if t, ok := value.(time.Time); ok && item.ValueType == QueryFieldDate && fc.dialect != "" {
+		return t.Format("2006-01-02")
+	}
+	return value
+}
+
+// ParseCustomFilters parse filters and search query like CustomFilters,
+// but return an error when the filters or search columns are malformed
+//
+//	=> Example
+//	filters, filterParams, search, searchParams, err := lib.ParseCustomFilters(c.Query("filters"), c.Query("search"), c.Query("search_columns"))
+//	if nil != err {
+//		return lib.ErrorBadRequest(c, err)
+//	}
+func ParseCustomFilters(QueryFilters, QuerySearch, columnFilter string) (string, []interface{}, string, []interface{}, error) {
+	return parseCustomFilters(QueryFilters, QuerySearch, columnFilter, nil, "")
+}
+
+func parseCustomFilters(QueryFilters, QuerySearch, columnFilter string, schema *QuerySchema, dialect FilterDialect) (string, []interface{}, string, []interface{}, error) {
+	compiler := filterCompiler{typed: nil != schema, dialect: dialect}
+	searchOptions := SearchOptions{}
+	if nil != schema {
+		searchOptions = schema.Search
+	}
+
+	ResultFilters := ""
+	whereFilters := []interface{}{}
+	ResultSearch := ""
+	whereSearch := []interface{}{}
+
+	columns, err := parseSearchColumns(columnFilter)
+	if nil == err && nil != schema {
+		columns, err = schema.searchColumns(columns)
+	} else if nil == err {
+		err = checkSearchColumns(columns)
+		for i, column := range columns {
+			columns[i] = dialect.Quote(column)
+		}
+	}
+	if nil != err {
+		return ResultFilters, whereFilters, ResultSearch, whereSearch, err
+	}
+
+	if QuerySearch != "" && len(columns) > 0 {
+		ResultSearch, whereSearch = searchOptions.condition(columns, QuerySearch, dialect)
+	}
+
+	expr, err := parseFilter(QueryFilters, schema)
+	if nil != err {
+		return ResultFilters, whereFilters, ResultSearch, whereSearch, err
+	}
+
+	ResultFilters, whereFilters, err = compiler.compile(expr)
+	return ResultFilters, whereFilters, ResultSearch, whereSearch, err
+}
+
+// checkSearchColumns search columns without schema become SQL text, only plain identifiers are allowed
+func checkSearchColumns(columns []string) error {
+	errs := FilterErrors{}
+	for i, column := range columns {
+		if !identifierPattern.MatchString(column) {
+			errs = append(errs, FilterError{Name: searchColumnParamName, Position: []int{i}, Value: column, Message: fmt.Sprintf("invalid search column %s", column)})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// parseSearchColumns parse search columns, ex: ["trx_id","id"]
+func parseSearchColumns(columnFilter string) ([]string, error) {
+	columns := []string{}
+	if strings.TrimSpace(columnFilter) == "" {
+		return columns, nil
+	}
+
+	var output interface{}
+	if err := JSONUnmarshal([]byte(columnFilter), &output); nil != err {
+		return columns, FilterErrors{{Name: searchColumnParamName, Value: columnFilter, Message: "search columns must be a valid JSON array"}}
+	}
+
+	values, ok := output.([]interface{})
+	if !ok {
+		return columns, FilterErrors{{Name: searchColumnParamName, Value: output, Message: "search columns must be a JSON array"}}
+	}
+
+	errs := FilterErrors{}
+	for i, value := range values {
+		column, ok := value.(string)
+		if !ok || strings.TrimSpace(column) == "" {
+			errs = append(errs, FilterError{Name: searchColumnParamName, Position: []int{i}, Value: value, Message: "search column must be a non-empty string"})
+			continue
+		}
+		columns = append(columns, column)
+	}
+
+	if len(errs) > 0 {
+		return columns, errs
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,216 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestParseFilter(t *testing.T) {
+	// single condition
+	expr, err := ParseFilter(`["id","=",1]`)
+	utils.AssertEqual(t, nil, err, "single condition")
+	query, params := expr.ToSQL()
+	utils.AssertEqual(t, "id = ?", query, "single condition query")
+	utils.AssertEqual(t, []interface{}{"1"}, params, "single condition params")
+
+	// implicit equal operator
+	expr, err = ParseFilter(`["status","active"]`)
+	utils.AssertEqual(t, nil, err, "implicit equal operator")
+	utils.AssertEqual(t, "=", expr.Items[0].Item.Operator, "implicit equal operator")
+
+	// AND takes precedence over OR
+	expr, err = ParseFilter(`[["id","=",1],["AND"],["status","=",true],["OR"],["amount","=",20.5]]`)
+	utils.AssertEqual(t, nil, err, "multiple conditions")
+	utils.AssertEqual(t, "OR", expr.Logic, "multiple conditions logic")
+	utils.AssertEqual(t, 2, len(expr.Items), "multiple conditions items")
+	query, params = expr.ToSQL()
+	utils.AssertEqual(t, "id = ? AND status = ? OR amount = ?", query, "multiple conditions query")
+	utils.AssertEqual(t, 3, len(params), "multiple conditions params")
+
+	// adjacent conditions are joined with OR
+	expr, err = ParseFilter(`[["id","=",1],["id","=",2]]`)
+	utils.AssertEqual(t, nil, err, "adjacent conditions")
+	utils.AssertEqual(t, "OR", expr.Logic, "adjacent conditions logic")
+
+	// IS NULL
+	expr, err = ParseFilter(`["deleted_at","is","null"]`)
+	utils.AssertEqual(t, nil, err, "is null")
+	query, _ = expr.ToSQL()
+	utils.AssertEqual(t, "(deleted_at IS NULL)", query, "is null query")
+
+	// joined field name
+	expr, _ = ParseFilter(`["product__name","LIKE","glass"]`)
+	utils.AssertEqual(t, "Product__name", expr.Items[0].Item.Field, "joined field name")
+
+	// empty filter
+	expr, err = ParseFilter(``)
+	utils.AssertEqual(t, nil, err, "empty filter")
+	utils.AssertEqual(t, true, expr.IsEmpty(), "empty filter")
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	_, err := ParseFilter(`unexpected json format`)
+	errs, ok := err.(FilterErrors)
+	utils.AssertEqual(t, true, ok, "invalid json")
+	utils.AssertEqual(t, "filters", errs[0].Path(), "invalid json path")
+
+	_, err = ParseFilter(`{"id":1}`)
+	utils.AssertEqual(t, true, nil != err, "object filter")
+
+	// number as field name
+	_, err = ParseFilter(`[["id","=",1],["AND"],[10,"=","waiting"]]`)
+	errs, _ = err.(FilterErrors)
+	utils.AssertEqual(t, 1, len(errs), "invalid field name")
+	utils.AssertEqual(t, "filters[2][0]", errs[0].Path(), "invalid field name path")
+
+	// unsupported operator
+	_, err = ParseFilter(`["id","; DROP TABLE users",1]`)
+	errs, _ = err.(FilterErrors)
+	utils.AssertEqual(t, "filters[1]", errs[0].Path(), "unsupported operator path")
+
+	// operator value validation
+	cases := []string{
+		`["id","IN","1"]`,
+		`["id","IN",[]]`,
+		`["id","BETWEEN",[1]]`,
+		`["id","IS",1]`,
+		`["id","=",[1,2]]`,
+		`["id","=",{"a":1}]`,
+		`["id","IN",[1,{"a":1}]]`,
+		`["id"]`,
+		`[["id","=",1],["XOR"],["id","=",2]]`,
+		`[["AND"],["id","=",1]]`,
+		`[["id","=",1],["AND"]]`,
+		`[["id","=",1],"id"]`,
+	}
+	for _, c := range cases {
+		_, err = ParseFilter(c)
+		utils.AssertEqual(t, true, nil != err, c)
+	}
+
+	// error details
+	_, err = ParseFilter(`[["id","=",1],["AND"],["status","IN","paid"]]`)
+	errs, _ = err.(FilterErrors)
+	data := errs.ErrorData()
+	utils.AssertEqual(t, 1, len(data), "error data")
+	utils.AssertEqual(t, "filters[2][2]", data[0].Path, "error data path")
+	utils.AssertEqual(t, "filter", data[0].Validator, "error data validator")
+}
+
+func TestParseCustomFilters(t *testing.T) {
+	filters, filterParams, search, searchParams, err := ParseCustomFilters(`["payment_status","IN",["paid","due"]]`, "value", `["trx_id","id"]`)
+	utils.AssertEqual(t, nil, err, "parse custom filters")
+	utils.AssertEqual(t, "payment_status IN ?", filters, "filters")
+	utils.AssertEqual(t, 1, len(filterParams), "filter params")
+	utils.AssertEqual(t, "trx_id LIKE ? OR id LIKE ?", search, "search")
+	utils.AssertEqual(t, 2, len(searchParams), "search params")
+
+	_, _, _, _, err = ParseCustomFilters("", "value", `["trx_id",1]`)
+	errs, _ := err.(FilterErrors)
+	utils.AssertEqual(t, "columns[1]", errs[0].Path(), "invalid search column")
+
+	// empty element is reported instead of panic
+	_, _, _, _, err = ParseCustomFilters(`[[],["AND"],["a","=",1]]`, "", "")
+	errs, _ = err.(FilterErrors)
+	utils.AssertEqual(t, 1, len(errs), "empty element")
+	utils.AssertEqual(t, "filters[0]", errs[0].Path(), "empty element path")
+
+	// legacy function fails closed on malformed filters instead of panic
+	filters, filterParams, _, _ = CustomFilters(`[[1,"=",1]]`, "", "")
+	utils.AssertEqual(t, "1 = 0", filters, "legacy malformed filters")
+	utils.AssertEqual(t, 0, len(filterParams), "legacy malformed filter params")
+
+	// a bad operator never drops the other conditions
+	filters, filterParams, _, _ = CustomFilters(`[["business_id","=","10"],["AND"],["status","~","x"]]`, "", "")
+	utils.AssertEqual(t, "1 = 0", filters, "legacy bad operator")
+	utils.AssertEqual(t, 0, len(filterParams), "legacy bad operator params")
+
+	// numbers of IN arrays are bound as their text
+	filters, filterParams, _, _ = CustomFilters(`["id","IN",[1,2.5,"A"]]`, "", "")
+	utils.AssertEqual(t, "id IN ?", filters, "legacy IN numbers")
+	utils.AssertEqual(t, []interface{}{[]interface{}{"1", "2.5", "a"}}, filterParams, "legacy IN number params")
+
+	// ILIKE is case-insensitive on every database
+	filters, filterParams, _, _ = CustomFilters(`[["name","ILIKE","jo hn"],["AND"],["code","NOT ILIKE","X"]]`, "", "")
+	utils.AssertEqual(t, "LOWER(name) LIKE LOWER(?) AND LOWER(code) NOT LIKE LOWER(?)", filters, "legacy ILIKE")
+	utils.AssertEqual(t, []interface{}{"%jo%hn%", "%X%"}, filterParams, "legacy ILIKE params")
+	expr, err := ParseFilter(`["name","ILIKE","jo"]`)
+	utils.AssertEqual(t, nil, err, "parse ILIKE")
+	query, _ := expr.ToDialectSQL(DialectPostgres)
+	utils.AssertEqual(t, `"name" ILIKE ?`, query, "postgres ILIKE")
+	query, _ = expr.ToDialectSQL(DialectMySQL)
+	utils.AssertEqual(t, "LOWER(`name`) LIKE LOWER(?)", query, "mysql ILIKE")
+}
+
+func TestFilterErrorsResponse(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		if _, err := ParseFilter(c.Query("filters")); nil != err {
+			return ErrorBadRequest(c, err)
+		}
+		return OK(c)
+	})
+
+	response, body, err := GetTest(app, `/?filters=[["id","IN","1"]]`, nil)
+	utils.AssertEqual(t, nil, err, "sending request")
+	utils.AssertEqual(t, 400, response.StatusCode, "bad request")
+	errorData, _ := body["error_data"].([]interface{})
+	utils.AssertEqual(t, 1, len(errorData), "error data")
+}
+
+func TestParseFilterGroups(t *testing.T) {
+	// explicit precedence
+	expr, err := ParseFilter(`[["status","IN",["x","y"]],["AND"],[["agent","=","A"],["OR"],["corporate","=","B"]]]`)
+	utils.AssertEqual(t, nil, err, "nested group")
+	utils.AssertEqual(t, "AND", expr.Logic, "nested group logic")
+	query, params := expr.ToSQL()
+	utils.AssertEqual(t, "status IN ? AND (agent = ? OR corporate = ?)", query, "nested group query")
+	utils.AssertEqual(t, 3, len(params), "nested group params")
+
+	// negated condition
+	expr, err = ParseFilter(`["NOT",["status","=","cancelled"]]`)
+	utils.AssertEqual(t, nil, err, "negated condition")
+	query, _ = expr.ToSQL()
+	utils.AssertEqual(t, "NOT (status = ?)", query, "negated condition query")
+
+	// negated group
+	expr, err = ParseFilter(`[["id","=",1],["AND"],["not",[["a","=",1],["OR"],["b","=",2]]]]`)
+	utils.AssertEqual(t, nil, err, "negated group")
+	query, _ = expr.ToSQL()
+	utils.AssertEqual(t, "id = ? AND NOT (a = ? OR b = ?)", query, "negated group query")
+
+	// double negation
+	expr, _ = ParseFilter(`["NOT",["NOT",["a","=",1]]]`)
+	query, _ = expr.ToSQL()
+	utils.AssertEqual(t, "a = ?", query, "double negation")
+
+	// group inside OR keeps precedence without parentheses
+	expr, _ = ParseFilter(`[["a","=",1],["OR"],[["b","=",2],["AND"],["c","=",3]]]`)
+	query, _ = expr.ToSQL()
+	utils.AssertEqual(t, "a = ? OR b = ? AND c = ?", query, "AND group inside OR")
+
+	// error position inside nested group
+	_, err = ParseFilter(`[["a","=",1],["AND"],[["b","=",2],["OR"],["c","IN",3]]]`)
+	errs, _ := err.(FilterErrors)
+	utils.AssertEqual(t, "filters[2][2][2]", errs[0].Path(), "nested error path")
+
+	_, err = ParseFilter(`["NOT",["a","LIKE",[1]]]`)
+	errs, _ = err.(FilterErrors)
+	utils.AssertEqual(t, "filters[1][2]", errs[0].Path(), "negation error path")
+
+	// nesting limit
+	deep := `["a","=",1]`
+	for i := 0; i <= filterMaxDepth; i++ {
+		deep = `[` + deep + `]`
+	}
+	_, err = ParseFilter(deep)
+	utils.AssertEqual(t, true, nil != err, "nesting limit")
+
+	// schema resolves nested fields
+	schema := sampleQuerySchema()
+	filters, _, _, _, err := schema.CustomFilters(`[["status","=","paid"],["AND"],["NOT",[["pax",">",2],["OR"],["is_active",false]]]]`, "", "")
+	utils.AssertEqual(t, nil, err, "schema nested group")
+	utils.AssertEqual(t, "status = ? AND NOT (pax > ? OR is_active = ?)", filters, "schema nested group query")
+}
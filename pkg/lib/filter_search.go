@@ -0,0 +1,140 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// search modes of SearchOptions
+const (
+	SearchModeContains = ""         // default, the whole term is matched with LIKE %term%, % and _ act as wildcards
+	SearchModeTerms    = "terms"    // escaped terms and quoted phrases, every term must match any search column
+	SearchModeFullText = "fulltext" // postgres full-text search, other dialects fall back to SearchModeTerms
+)
+
+const (
+	searchEscape          = "!"      // LIKE escape character, backslash is a string escape in MySQL
+	defaultSearchLanguage = "simple" // postgres text search configuration
+)
+
+// SearchOptions search query behaviour of QuerySchema
+//
+//	=> Example
+//	schema := lib.QuerySchema{Search: lib.SearchOptions{Mode: lib.SearchModeTerms}, Fields: fields}
+//	// search=john "bali beach" -> every term must be found in any search column
+type SearchOptions struct {
+	Mode     string // SearchModeContains, SearchModeTerms or SearchModeFullText
+	Language string // postgres text search configuration of SearchModeFullText, default `simple`
+}
+
+// ParseSearchTerms split search query into terms, quoted phrases are kept as a single term
+//
+//	ParseSearchTerms(`john "bali beach"`) // [john, bali beach]
+func ParseSearchTerms(search string) []string {
+	terms := []string{}
+	term := strings.Builder{}
+	quoted := false
+	flush := func() {
+		if t := strings.TrimSpace(term.String()); t != "" {
+			terms = append(terms, t)
+		}
+		term.Reset()
+	}
+
+	for _, r := range search {
+		switch {
+		case r == '"':
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			term.WriteRune(r)
+		}
+	}
+	flush()
+
+	return terms
+}
+
+// EscapeLike escape LIKE metacharacters, the pattern must be used with ESCAPE '!'
+func EscapeLike(value string) string {
+	return strings.NewReplacer(searchEscape, searchEscape+searchEscape, "%", searchEscape+"%", "_", searchEscape+"_").Replace(value)
+}
+
+// Condition compile search query on columns into where clause and its bind parameters,
+// columns are names quoted by the dialect, see FilterDialect.Quote
+func (o SearchOptions) Condition(columns []string, search string, dialect FilterDialect) (string, []interface{}) {
+	quoted := []string{}
+	for _, column := range columns {
+		quoted = append(quoted, dialect.Quote(column))
+	}
+	return o.condition(quoted, search, dialect)
+}
+
+// condition search condition of SQL columns, columns are quoted or declared by QuerySchema
+func (o SearchOptions) condition(columns []string, search string, dialect FilterDialect) (string, []interface{}) {
+	if len(columns) == 0 || search == "" {
+		return "", []interface{}{}
+	}
+
+	switch o.Mode {
+	case SearchModeFullText:
+		if strings.TrimSpace(search) == "" {
+			return "", []interface{}{}
+		}
+		if dialect == "" || dialect == DialectPostgres {
+			return o.fullText(columns, search, dialect)
+		}
+		return o.terms(columns, search, dialect)
+	case SearchModeTerms:
+		return o.terms(columns, search, dialect)
+	}
+
+	querySearch := []string{}
+	whereSearch := []interface{}{}
+	for _, column := range columns {
+		if dialect == "" {
+			querySearch = append(querySearch, column+" LIKE ?")
+		} else {
+			querySearch = append(querySearch, dialect.Like(column, false))
+		}
+		whereSearch = append(whereSearch, "%"+search+"%")
+	}
+	return strings.Join(querySearch, " OR "), whereSearch
+}
+
+// terms every term must match any column
+//
+//	(a LIKE ? OR b LIKE ?) AND (a LIKE ? OR b LIKE ?)
+func (o SearchOptions) terms(columns []string, search string, dialect FilterDialect) (string, []interface{}) {
+	querySearch := []string{}
+	whereSearch := []interface{}{}
+	for _, term := range ParseSearchTerms(search) {
+		pattern := "%" + EscapeLike(term) + "%"
+		conditions := []string{}
+		for _, column := range columns {
+			condition := column + " LIKE ?"
+			if dialect != "" {
+				condition = dialect.Like(column, false)
+			}
+			conditions = append(conditions, condition+" ESCAPE '"+searchEscape+"'")
+			whereSearch = append(whereSearch, pattern)
+		}
+		querySearch = append(querySearch, "("+strings.Join(conditions, " OR ")+")")
+	}
+
+	return strings.Join(querySearch, " AND "), whereSearch
+}
+
+// fullText postgres full-text search, quoted phrases and terms are parsed by websearch_to_tsquery
+func (o SearchOptions) fullText(columns []string, search string, dialect FilterDialect) (string, []interface{}) {
+	language := o.Language
+	if !identifierPattern.MatchString(language) {
+		language = defaultSearchLanguage
+	}
+
+	return fmt.Sprintf("to_tsvector('%s', concat_ws(' ', %s)) @@ websearch_to_tsquery('%s', ?)",
+		language, strings.Join(columns, ", "), language), []interface{}{search}
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestParseSearchTerms(t *testing.T) {
+	utils.AssertEqual(t, []string{"john", "bali beach", "50%"}, ParseSearchTerms(` john "bali beach"  50% `), "terms")
+	utils.AssertEqual(t, []string{"a", "b c"}, ParseSearchTerms(`a "b c`), "unclosed phrase")
+	utils.AssertEqual(t, []string{}, ParseSearchTerms(` "" `), "empty")
+	utils.AssertEqual(t, "100!% !_id !!", EscapeLike("100% _id !"), "escape")
+}
+
+func TestSearchOptionsCondition(t *testing.T) {
+	columns := []string{"name", "code"}
+
+	query, params := SearchOptions{}.Condition(columns, "50% off", "")
+	utils.AssertEqual(t, "name LIKE ? OR code LIKE ?", query, "contains")
+	utils.AssertEqual(t, []interface{}{"%50% off%", "%50% off%"}, params, "contains params")
+
+	query, params = SearchOptions{Mode: SearchModeTerms}.Condition(columns, `50% "bali beach"`, "")
+	utils.AssertEqual(t, "(name LIKE ? ESCAPE '!' OR code LIKE ? ESCAPE '!') AND (name LIKE ? ESCAPE '!' OR code LIKE ? ESCAPE '!')", query, "terms")
+	utils.AssertEqual(t, []interface{}{"%50!%%", "%50!%%", "%bali beach%", "%bali beach%"}, params, "terms params")
+
+	query, _ = SearchOptions{Mode: SearchModeTerms}.Condition([]string{"name"}, "bali", DialectMySQL)
+	utils.AssertEqual(t, "(LOWER(`name`) LIKE LOWER(?) ESCAPE '!')", query, "terms mysql")
+
+	query, params = SearchOptions{Mode: SearchModeFullText, Language: "english"}.Condition(columns, `"bali beach" -resort`, DialectPostgres)
+	utils.AssertEqual(t, `to_tsvector('english', concat_ws(' ', "name", "code")) @@ websearch_to_tsquery('english', ?)`, query, "full text")
+	utils.AssertEqual(t, []interface{}{`"bali beach" -resort`}, params, "full text params")
+
+	query, _ = SearchOptions{Mode: SearchModeFullText, Language: "x'; DROP"}.Condition([]string{"name"}, "bali", "")
+	utils.AssertEqual(t, "to_tsvector('simple', concat_ws(' ', name)) @@ websearch_to_tsquery('simple', ?)", query, "invalid language")
+
+	query, _ = SearchOptions{Mode: SearchModeFullText}.Condition([]string{"name"}, "bali", DialectSQLite)
+	utils.AssertEqual(t, `(LOWER("name") LIKE LOWER(?) ESCAPE '!')`, query, "full text fallback")
+
+	query, _ = SearchOptions{Mode: SearchModeTerms}.Condition(columns, `  ""  `, "")
+	utils.AssertEqual(t, "", query, "no terms")
+
+	// schema search mode
+	schema := QuerySchema{Search: SearchOptions{Mode: SearchModeTerms}, Fields: map[string]QueryField{
+		"name": {Type: QueryFieldString, Searchable: true},
+	}}
+	_, _, query, _, err := schema.CustomFilters("", "john doe", "")
+	utils.AssertEqual(t, nil, err, "schema search")
+	utils.AssertEqual(t, "(name LIKE ? ESCAPE '!') AND (name LIKE ? ESCAPE '!')", query, "schema search")
+}
+
+func TestSearchConditionColumns(t *testing.T) {
+	query, _ := SearchOptions{}.Condition([]string{"name) OR 1=1 --"}, "x", DialectPostgres)
+	utils.AssertEqual(t, `"name) OR 1=1 --" ILIKE ?`, query, "client names never become expressions")
+
+	// only columns declared by the schema are expressions
+	schema := QuerySchema{Dialect: DialectPostgres, Fields: map[string]QueryField{
+		"full_name": {Column: "concat(first_name, ' ', last_name)", Searchable: true},
+		"code":      {Searchable: true},
+	}}
+	_, _, search, _, err := schema.CustomFilters("", "x", `["full_name","code"]`)
+	utils.AssertEqual(t, nil, err, "schema search")
+	utils.AssertEqual(t, `concat(first_name, ' ', last_name) ILIKE ? OR "code" ILIKE ?`, search, "declared expression")
+}
@@ -0,0 +1,167 @@
+package lib
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+// identity headers
+const (
+	HeaderXUserID      = "X-User-ID"
+	HeaderXAgentID     = "X-Agent-ID"
+	HeaderXCorporateID = "X-Corporate-ID"
+)
+
+// identityLocal fiber locals key of Identity, set by IdentityMiddleware
+const identityLocal = "lib.identity"
+
+// identityContextKey context.Context key of Identity
+type identityContextKey struct{}
+
+// Identity request identity parsed from x-user-id, x-agent-id, x-corporate-id and accept-language headers
+type Identity struct {
+	UserID      *uuid.UUID `json:"user_id"`
+	AgentID     *uuid.UUID `json:"agent_id"` // AGENT_ID config is used when the header is missing and not required
+	CorporateID *uuid.UUID `json:"corporate_id"`
+	Language    string     `json:"language"`
+}
+
+// IdentityConfig IdentityMiddleware options
+type IdentityConfig struct {
+	Next             func(c *fiber.Ctx) bool // skip the middleware when returns true
+	RequireUser      bool                    // respond 401 when x-user-id is missing
+	RequireAgent     bool                    // respond 401 when x-agent-id is missing, the AGENT_ID config does not satisfy it
+	RequireCorporate bool                    // respond 401 when x-corporate-id is missing
+}
+
+// IdentityMiddleware parse identity headers once into c.Locals and the user context,
+// invalid ids respond 400 and missing required ids respond 401
+//
+//	=> Example
+//	app.Use(lib.IdentityMiddleware(lib.IdentityConfig{RequireUser: true}))
+//	app.Get("/bookings", func(c *fiber.Ctx) error {
+//		identity := lib.GetIdentity(c)
+//		return service.ListBookings(c.UserContext(), *identity.UserID) // lib.IdentityFromContext(ctx)
+//	})
+func IdentityMiddleware(config ...IdentityConfig) fiber.Handler {
+	cfg := IdentityConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	return func(c *fiber.Ctx) error {
+		if nil != cfg.Next && cfg.Next(c) {
+			return c.Next()
+		}
+
+		identity, errs := parseIdentity(c)
+		if len(errs) > 0 {
+			return ErrorBadRequest(c, Response{
+				Message:          "Bad Request",
+				ErrorDescription: Strptr("Invalid identity headers"),
+				ErrorData:        &errs,
+			})
+		}
+
+		required := []struct {
+			require bool
+			id      *uuid.UUID
+			header  string
+		}{
+			{cfg.RequireUser, identity.UserID, HeaderXUserID},
+			{cfg.RequireAgent, identity.AgentID, HeaderXAgentID},
+			{cfg.RequireCorporate, identity.CorporateID, HeaderXCorporateID},
+		}
+		for _, r := range required {
+			if r.require && nil == r.id {
+				return ErrorUnauthorized(c, r.header+" header is required")
+			}
+		}
+		identity = identity.withDefaults()
+
+		c.Locals(identityLocal, &identity)
+		c.SetUserContext(ContextWithIdentity(c.UserContext(), identity))
+
+		return c.Next()
+	}
+}
+
+// GetIdentity identity of the request, headers are parsed when IdentityMiddleware is not used
+func GetIdentity(c *fiber.Ctx) Identity {
+	if identity, ok := c.Locals(identityLocal).(*Identity); ok {
+		return *identity
+	}
+	identity, _ := parseIdentity(c)
+	return identity.withDefaults()
+}
+
+// ContextWithIdentity copy of context with identity
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext identity of context created by IdentityMiddleware or ContextWithIdentity
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(Identity)
+	return identity, ok
+}
+
+// Headers identity headers for downstream service calls
+//
+//	client.Headers = identity.Headers()
+func (i Identity) Headers() map[string]string {
+	headers := map[string]string{}
+	if nil != i.UserID {
+		headers[HeaderXUserID] = i.UserID.String()
+	}
+	if nil != i.AgentID {
+		headers[HeaderXAgentID] = i.AgentID.String()
+	}
+	if nil != i.CorporateID {
+		headers[HeaderXCorporateID] = i.CorporateID.String()
+	}
+	if i.Language != "" {
+		headers[fiber.HeaderAcceptLanguage] = i.Language
+	}
+	return headers
+}
+
+// parseIdentity parse identity headers without config defaults, invalid ids are reported as error data
+func parseIdentity(c *fiber.Ctx) (Identity, []ErrorData) {
+	identity := Identity{Language: GetLanguage(c)}
+	errs := []ErrorData{}
+
+	parse := func(header string) *uuid.UUID {
+		value := c.Get(header)
+		if value == "" {
+			return nil
+		}
+		id, err := uuid.Parse(value)
+		if nil != err {
+			errorData := ErrorData{Name: header, Path: header, Type: "UUID", Value: value, Validator: "uuid"}
+			errorData.Message = ValidationMessage(identity.Language, errorData)
+			errs = append(errs, errorData)
+			return nil
+		}
+		return &id
+	}
+
+	identity.UserID = parse(HeaderXUserID)
+	identity.AgentID = parse(HeaderXAgentID)
+	identity.CorporateID = parse(HeaderXCorporateID)
+
+	return identity, errs
+}
+
+// withDefaults identity with AGENT_ID config when x-agent-id is missing
+func (i Identity) withDefaults() Identity {
+	if nil == i.AgentID {
+		if id, err := uuid.Parse(viper.GetString("AGENT_ID")); nil == err {
+			i.AgentID = &id
+		}
+	}
+	return i
+}
@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+func TestIdentityMiddleware(t *testing.T) {
+	userID := uuid.New()
+	corporateID := uuid.New()
+
+	app := fiber.New()
+	app.Use(IdentityMiddleware(IdentityConfig{
+		RequireUser: true,
+		Next:        func(c *fiber.Ctx) bool { return c.Path() == "/public" },
+	}))
+	app.Get("/me", func(c *fiber.Ctx) error {
+		identity := GetIdentity(c)
+		fromContext, ok := IdentityFromContext(c.UserContext())
+		return c.JSON(fiber.Map{
+			"user_id":      identity.UserID,
+			"corporate_id": GetXCorporateID(c),
+			"agent_id":     identity.AgentID,
+			"language":     identity.Language,
+			"context":      ok && *fromContext.UserID == *identity.UserID,
+			"headers":      identity.Headers(),
+		})
+	})
+	app.Get("/public", func(c *fiber.Ctx) error {
+		return c.JSON(GetIdentity(c))
+	})
+
+	response, body, err := GetTest(app, "/me", map[string]string{
+		HeaderXUserID:              userID.String(),
+		HeaderXCorporateID:         corporateID.String(),
+		fiber.HeaderAcceptLanguage: "id",
+	})
+	utils.AssertEqual(t, nil, err, "sending request")
+	utils.AssertEqual(t, 200, response.StatusCode, "status code")
+	utils.AssertEqual(t, userID.String(), body["user_id"], "user id")
+	utils.AssertEqual(t, corporateID.String(), body["corporate_id"], "corporate id accessor")
+	utils.AssertEqual(t, nil, body["agent_id"], "missing agent id")
+	utils.AssertEqual(t, "id", body["language"], "language")
+	utils.AssertEqual(t, true, body["context"], "context propagation")
+	headers := body["headers"].(map[string]interface{})
+	utils.AssertEqual(t, userID.String(), headers[HeaderXUserID], "forwarded user id")
+	utils.AssertEqual(t, nil, headers[HeaderXAgentID], "no agent header")
+
+	response, _, _ = GetTest(app, "/me", nil)
+	utils.AssertEqual(t, 401, response.StatusCode, "required user")
+
+	response, body, _ = GetTest(app, "/me", map[string]string{HeaderXUserID: userID.String(), HeaderXAgentID: "agent-1"})
+	utils.AssertEqual(t, 400, response.StatusCode, "invalid agent id")
+	errs := body["error_data"].([]interface{})
+	utils.AssertEqual(t, HeaderXAgentID, errs[0].(map[string]interface{})["path"], "invalid header path")
+
+	response, body, _ = GetTest(app, "/public", map[string]string{HeaderXUserID: "invalid"})
+	utils.AssertEqual(t, 200, response.StatusCode, "skipped middleware")
+	utils.AssertEqual(t, nil, body["user_id"], "invalid id is ignored without middleware")
+}
+
+func TestIdentityRequireAgent(t *testing.T) {
+	agentID := uuid.New()
+	viper.Set("AGENT_ID", agentID.String())
+	defer viper.Set("AGENT_ID", "")
+
+	app := fiber.New()
+	app.Get("/optional", IdentityMiddleware(), func(c *fiber.Ctx) error {
+		return c.JSON(GetIdentity(c))
+	})
+	app.Get("/required", IdentityMiddleware(IdentityConfig{RequireAgent: true}), func(c *fiber.Ctx) error {
+		return c.JSON(GetIdentity(c))
+	})
+
+	response, body, _ := GetTest(app, "/optional", nil)
+	utils.AssertEqual(t, 200, response.StatusCode, "optional agent")
+	utils.AssertEqual(t, agentID.String(), body["agent_id"], "AGENT_ID config fallback")
+
+	response, _, _ = GetTest(app, "/required", nil)
+	utils.AssertEqual(t, 401, response.StatusCode, "AGENT_ID config does not satisfy required agent")
+
+	headerID := uuid.New()
+	response, body, _ = GetTest(app, "/required", map[string]string{HeaderXAgentID: headerID.String()})
+	utils.AssertEqual(t, 200, response.StatusCode, "required agent header")
+	utils.AssertEqual(t, headerID.String(), body["agent_id"], "header takes precedence")
+}
@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// defaultMessageLanguage last language of every fallback chain
+const defaultMessageLanguage = "en"
+
+//go:embed messages/*.json
+var embeddedMessages embed.FS
+
+// Messages default message catalogue, loaded with the embedded bundles,
+// used by Send and ErrorConflict to render ErrorData.Message
+//
+//	=> Example
+//	lib.Messages.LoadDir("./messages") // messages/fr.json, messages/id.json ...
+//	lib.Messages.Add("id", map[string]string{"required": "{field} tidak boleh kosong"})
+var Messages = func() *MessageCatalog {
+	catalog := NewMessageCatalog()
+	if err := catalog.LoadFS(embeddedMessages, "messages"); nil != err {
+		panic(err)
+	}
+	return catalog
+}()
+
+// MessageCatalog localized message bundles keyed by language
+//
+//	message placeholders:
+//	{field}    -> field name
+//	{criteria} -> validator parameter, example: 10 of gte=10
+//	{value}    -> field value
+type MessageCatalog struct {
+	mu       sync.RWMutex
+	bundles  map[string]map[string]string
+	fallback []string
+}
+
+// NewMessageCatalog create empty message catalogue, fallback languages are used
+// after the requested and LANGUAGE configured languages, default is en
+func NewMessageCatalog(fallback ...string) *MessageCatalog {
+	if len(fallback) == 0 {
+		fallback = []string{defaultMessageLanguage}
+	}
+	return &MessageCatalog{bundles: map[string]map[string]string{}, fallback: fallback}
+}
+
+// Add add or replace messages of a language
+func (m *MessageCatalog) Add(lang string, messages map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lang = strings.ToLower(lang)
+	if _, ok := m.bundles[lang]; !ok {
+		m.bundles[lang] = map[string]string{}
+	}
+	for key, message := range messages {
+		m.bundles[lang][key] = message
+	}
+}
+
+// LoadFS load <lang>.json bundles from a directory of the file system, ex: embedded files
+func (m *MessageCatalog) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if nil != err {
+		return err
+	}
+
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if nil != err {
+			return err
+		}
+		messages := map[string]string{}
+		if err := JSONUnmarshal(content, &messages); nil != err {
+			return fmt.Errorf("message bundle %s: %s", file, err.Error())
+		}
+		m.Add(strings.TrimSuffix(path.Base(file), ".json"), messages)
+	}
+
+	return nil
+}
+
+// LoadDir load <lang>.json bundles from a directory
+func (m *MessageCatalog) LoadDir(dir string) error {
+	return m.LoadFS(os.DirFS(dir), ".")
+}
+
+// Languages languages which have a bundle
+func (m *MessageCatalog) Languages() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	languages := []string{}
+	for lang := range m.bundles {
+		languages = append(languages, lang)
+	}
+	return languages
+}
+
+// Translate render message of the key in the first language of the fallback chain having the key:
+// requested language, its base language (pt-br -> pt), LANGUAGE config, then catalogue fallback
+func (m *MessageCatalog) Translate(lang, key string, params map[string]interface{}) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, candidate := range m.chain(lang) {
+		if message, ok := m.bundles[candidate][key]; ok {
+			return renderMessage(message, params), true
+		}
+	}
+
+	return "", false
+}
+
+func (m *MessageCatalog) chain(lang string) []string {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	chain := []string{lang}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		chain = append(chain, base)
+	}
+	if configured := viper.GetString("LANGUAGE"); configured != "" {
+		chain = append(chain, strings.ToLower(configured))
+	}
+	return append(chain, m.fallback...)
+}
+
+// renderMessage replace {name} placeholders with params
+func renderMessage(message string, params map[string]interface{}) string {
+	replacements := []string{}
+	for name, value := range params {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprintf("%v", value))
+	}
+	return strings.TrimSpace(strings.NewReplacer(replacements...).Replace(message))
+}
+
+// ValidationMessage localized message of a field error, the default message is used for unknown validators
+func ValidationMessage(lang string, errorData ErrorData) string {
+	params := map[string]interface{}{
+		"field":    errorData.Name,
+		"criteria": errorData.Criteria,
+		"value":    errorData.Value,
+	}
+	for name, value := range params {
+		if nil == value {
+			params[name] = ""
+		}
+	}
+	if message, ok := Messages.Translate(lang, errorData.Validator, params); ok {
+		return message
+	}
+	if message, ok := Messages.Translate(lang, "default", params); ok {
+		return message
+	}
+	return fmt.Sprintf("Invalid data %v", errorData.Name)
+}
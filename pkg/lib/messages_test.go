@@ -0,0 +1,67 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestMessageCatalog(t *testing.T) {
+	catalog := NewMessageCatalog()
+	err := catalog.LoadFS(fstest.MapFS{
+		"bundles/en.json": {Data: []byte(`{"required": "{field} is required", "gte": "{field} must be at least {criteria}"}`)},
+		"bundles/id.json": {Data: []byte(`{"required": "{field} wajib diisi"}`)},
+	}, "bundles")
+	utils.AssertEqual(t, nil, err, "load fs")
+	utils.AssertEqual(t, 2, len(catalog.Languages()), "languages")
+
+	message, ok := catalog.Translate("id", "required", map[string]interface{}{"field": "name"})
+	utils.AssertEqual(t, true, ok, "translated")
+	utils.AssertEqual(t, "name wajib diisi", message, "requested language")
+
+	message, _ = catalog.Translate("id-ID", "gte", map[string]interface{}{"field": "age", "criteria": 17})
+	utils.AssertEqual(t, "age must be at least 17", message, "fallback language")
+
+	message, _ = catalog.Translate("pt-BR", "required", map[string]interface{}{"field": "name"})
+	utils.AssertEqual(t, "name is required", message, "unknown language")
+
+	_, ok = catalog.Translate("en", "unknown", nil)
+	utils.AssertEqual(t, false, ok, "unknown key")
+
+	catalog.Add("ID", map[string]string{"required": "{field} tidak boleh kosong"})
+	message, _ = catalog.Translate("id", "required", map[string]interface{}{"field": "name"})
+	utils.AssertEqual(t, "name tidak boleh kosong", message, "override")
+
+	dir := t.TempDir()
+	utils.AssertEqual(t, nil, os.WriteFile(filepath.Join(dir, "fr.json"), []byte(`{"required": "{field} est obligatoire"}`), 0600), "write bundle")
+	utils.AssertEqual(t, nil, catalog.LoadDir(dir), "load dir")
+	message, _ = catalog.Translate("fr", "required", map[string]interface{}{"field": "nom"})
+	utils.AssertEqual(t, "nom est obligatoire", message, "loaded directory")
+
+	utils.AssertEqual(t, nil, os.WriteFile(filepath.Join(dir, "es.json"), []byte(`{"required": 1}`), 0600), "write invalid bundle")
+	utils.AssertEqual(t, true, nil != catalog.LoadDir(dir), "invalid bundle")
+}
+
+func TestValidationMessage(t *testing.T) {
+	utils.AssertEqual(t, "name is required", ValidationMessage("en", ErrorData{Name: "name", Validator: "required"}), "english")
+	utils.AssertEqual(t, "age harus lebih dari atau sama dengan 17", ValidationMessage("id", ErrorData{Name: "age", Validator: "gte", Criteria: "17"}), "indonesian")
+	utils.AssertEqual(t, "Invalid data code", ValidationMessage("en", ErrorData{Name: "code", Validator: "unknown"}), "default message")
+
+	type payload struct {
+		Name string `json:"name" validate:"required"`
+	}
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return ErrorBadRequest(c, VALIDATOR.Struct(payload{}))
+	})
+
+	_, body, err := GetTest(app, "/", map[string]string{fiber.HeaderAcceptLanguage: "id"})
+	utils.AssertEqual(t, nil, err, "sending request")
+	errs, _ := body["error_data"].([]interface{})
+	utils.AssertEqual(t, 1, len(errs), "error data")
+	utils.AssertEqual(t, "name wajib diisi", errs[0].(map[string]interface{})["message"], "localized message")
+}
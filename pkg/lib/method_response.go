@@ -12,27 +12,59 @@ import (
 type errorCode int
 
 const (
-	_                     errorCode = 0
-	errorCodeBadRequest   errorCode = 400
-	errorCodeUnauthorized errorCode = 401
-	errorCodeNotFound     errorCode = 404
-	errorCodeTimeout      errorCode = 408
-	errorCodeConflict     errorCode = 409
-	errorCodeGone         errorCode = 410
-	errorCodeInternal     errorCode = 500
+	_                        errorCode = 0
+	errorCodeBadRequest      errorCode = 400
+	errorCodeUnauthorized    errorCode = 401
+	errorCodeForbidden       errorCode = 403
+	errorCodeNotFound        errorCode = 404
+	errorCodeNotAllowed      errorCode = 405
+	errorCodeTimeout         errorCode = 408
+	errorCodeConflict        errorCode = 409
+	errorCodeGone            errorCode = 410
+	errorCodeTooLarge        errorCode = 413
+	errorCodeUnsupported     errorCode = 415
+	errorCodeUnprocessable   errorCode = 422
+	errorCodeTooManyRequests errorCode = 429
+	errorCodeInternal        errorCode = 500
+	errorCodeUnavailable     errorCode = 503
 )
 
 const (
-	_                        string = ""
-	errorMessageBadRequest   string = "Bad request"
-	errorMessageUnauthorized string = "Unauthorized"
-	errorMessageNotFound     string = "Not found"
-	errorMessageTimeout      string = "Timeout"
-	errorMessageConflict     string = "Conflict"
-	errorMessageGone         string = "Gone"
-	errorMessageInternal     string = "Internal"
+	_                           string = ""
+	errorMessageBadRequest      string = "Bad request"
+	errorMessageUnauthorized    string = "Unauthorized"
+	errorMessageForbidden       string = "Forbidden"
+	errorMessageNotFound        string = "Not found"
+	errorMessageNotAllowed      string = "Not Allowed"
+	errorMessageTimeout         string = "Timeout"
+	errorMessageConflict        string = "Conflict"
+	errorMessageGone            string = "Gone"
+	errorMessageTooLarge        string = "Request entity too large"
+	errorMessageUnsupported     string = "Unsupported media type"
+	errorMessageUnprocessable   string = "Unprocessable entity"
+	errorMessageTooManyRequests string = "Too many requests"
+	errorMessageInternal        string = "Internal"
+	errorMessageUnavailable     string = "Service unavailable"
 )
 
+// errorMessages default description of error codes
+var errorMessages = map[errorCode]string{
+	errorCodeBadRequest:      errorMessageBadRequest,
+	errorCodeUnauthorized:    errorMessageUnauthorized,
+	errorCodeForbidden:       errorMessageForbidden,
+	errorCodeNotFound:        errorMessageNotFound,
+	errorCodeNotAllowed:      errorMessageNotAllowed,
+	errorCodeTimeout:         errorMessageTimeout,
+	errorCodeConflict:        errorMessageConflict,
+	errorCodeGone:            errorMessageGone,
+	errorCodeTooLarge:        errorMessageTooLarge,
+	errorCodeUnsupported:     errorMessageUnsupported,
+	errorCodeUnprocessable:   errorMessageUnprocessable,
+	errorCodeTooManyRequests: errorMessageTooManyRequests,
+	errorCodeInternal:        errorMessageInternal,
+	errorCodeUnavailable:     errorMessageUnavailable,
+}
+
 func SetErrorBadRequest(description ...string) (errResp ErrorResponse) {
 	errResp.setCode(errorCodeBadRequest)
 	errResp.setDescription(description...)
@@ -45,12 +77,24 @@ func SetErrorUnauthorized(description ...string) (errResp ErrorResponse) {
 	return
 }
 
+func SetErrorForbidden(description ...string) (errResp ErrorResponse) {
+	errResp.setCode(errorCodeForbidden)
+	errResp.setDescription(description...)
+	return
+}
+
 func SetErrorNotFound(description ...string) (errResp ErrorResponse) {
 	errResp.setCode(errorCodeNotFound)
 	errResp.setDescription(description...)
 	return
 }
 
+func SetErrorNotAllowed(description ...string) (errResp ErrorResponse) {
+	errResp.setCode(errorCodeNotAllowed)
+	errResp.setDescription(description...)
+	return
+}
+
 func SetErrorTimeout(description ...string) (errResp ErrorResponse) {
 	errResp.setCode(errorCodeTimeout)
 	errResp.setDescription(description...)
@@ -69,26 +113,99 @@ func SetErrorGone(description ...string) (errResp ErrorResponse) {
 	return
 }
 
+func SetErrorRequestEntityTooLarge(description ...string) (errResp ErrorResponse) {
+	errResp.setCode(errorCodeTooLarge)
+	errResp.setDescription(description...)
+	return
+}
+
+func SetErrorUnsupportedMediaType(description ...string) (errResp ErrorResponse) {
+	errResp.setCode(errorCodeUnsupported)
+	errResp.setDescription(description...)
+	return
+}
+
+func SetErrorUnprocessableEntity(description ...string) (errResp ErrorResponse) {
+	errResp.setCode(errorCodeUnprocessable)
+	errResp.setDescription(description...)
+	return
+}
+
+func SetErrorTooManyRequests(description ...string) (errResp ErrorResponse) {
+	errResp.setCode(errorCodeTooManyRequests)
+	errResp.setDescription(description...)
+	return
+}
+
 func SetErrorInternal(description ...string) (errResp ErrorResponse) {
 	errResp.setCode(errorCodeInternal)
 	errResp.setDescription(description...)
 	return
 }
 
+func SetErrorServiceUnavailable(description ...string) (errResp ErrorResponse) {
+	errResp.setCode(errorCodeUnavailable)
+	errResp.setDescription(description...)
+	return
+}
+
+// ErrorResponse error with http status, field error details and optional cause
+//
+//	=> Example
+//	if nil != err {
+//		return lib.SetErrorNotFound("Booking not found").WithCause(err)
+//	}
 type ErrorResponse struct {
 	code        errorCode
 	description string
+	errorData   []ErrorData
+	cause       error
 }
 
 type IErrorResponse interface {
+	error
 	Code() (code int)
 	Description() (description string)
+	ErrorData() []ErrorData
 	IsEmpty() (isEmpty bool)
 	SendToContext(c *fiber.Ctx) (err error)
 	setCode(code errorCode)
 	setDescription(description ...string)
 }
 
+// Error description, cause or default message of the code
+func (e ErrorResponse) Error() string {
+	switch {
+	case e.description != "":
+		return e.description
+	case nil != e.cause:
+		return e.cause.Error()
+	}
+	return errorMessages[e.code]
+}
+
+// Unwrap cause of the error
+func (e ErrorResponse) Unwrap() error {
+	return e.cause
+}
+
+// WithCause copy of the error with cause, the cause is not sent to the client except translated database errors of conflict
+func (e ErrorResponse) WithCause(cause error) ErrorResponse {
+	e.cause = cause
+	return e
+}
+
+// WithErrorData copy of the error with field error details
+func (e ErrorResponse) WithErrorData(errorData ...ErrorData) ErrorResponse {
+	e.errorData = append(append([]ErrorData{}, e.errorData...), errorData...)
+	return e
+}
+
+// ErrorData field error details
+func (e ErrorResponse) ErrorData() []ErrorData {
+	return e.errorData
+}
+
 func (e ErrorResponse) Code() (code int) {
 	if e.code == 0 {
 		log.Printf("ErrorResponse_Code, code: %d is undefined", code)
@@ -103,79 +220,37 @@ func (e ErrorResponse) Description() (description string) {
 }
 
 func (e ErrorResponse) IsEmpty() (isEmpty bool) {
-	if (e == ErrorResponse{}) {
+	if e.code == 0 && e.description == "" && len(e.errorData) == 0 && nil == e.cause {
 		isEmpty = true
 	}
 	return
 }
 
 func (e ErrorResponse) SendToContext(c *fiber.Ctx) (err error) {
-	switch e.code {
-	case errorCodeBadRequest:
-		{
-			if len(e.description) == 0 {
-				e.description = errorMessageBadRequest
-			}
-			err = ErrorBadRequest(c, e.description)
-			break
-		}
-	case errorCodeUnauthorized:
-		{
-			if len(e.description) == 0 {
-				e.description = errorMessageUnauthorized
-			}
-			err = ErrorUnauthorized(c, e.description)
-			break
-		}
-	case errorCodeNotFound:
-		{
-			if len(e.description) == 0 {
-				e.description = errorMessageNotFound
-			}
-			err = ErrorNotFound(c, e.description)
-			break
-		}
-	case errorCodeTimeout:
-		{
-			if len(e.description) == 0 {
-				e.description = errorMessageTimeout
-			}
-			err = ErrorTimeout(c, e.description)
-			break
-		}
-	case errorCodeConflict:
-		{
-			if len(e.description) == 0 {
-				e.description = errorMessageConflict
-			}
-			err = ErrorConflict(c, e.description)
-			break
-		}
-	case errorCodeGone:
-		{
-			if len(e.description) == 0 {
-				e.description = errorMessageGone
-			}
-			err = ErrorGone(c, e.description)
-			break
-		}
-	case errorCodeInternal:
-		{
-			if len(e.description) == 0 {
-				e.description = errorMessageInternal
-			}
-			err = ErrorInternal(c, e.description)
-			break
-		}
-	default:
-		{
-			log.Printf("ErrorResponse_SendToContext, code: %d is undefined", e.code)
-			err = ErrorInternal(c)
-			break
+	message, ok := errorMessages[e.code]
+	if !ok {
+		log.Printf("ErrorResponse_SendToContext, code: %d is undefined", e.code)
+		return ErrorInternal(c)
+	}
+	if len(e.description) == 0 {
+		e.description = message
+	}
+
+	// database errors are translated into field errors
+	if e.code == errorCodeConflict && len(e.errorData) == 0 {
+		if dbErr, ok := DBErrors.Translate(e.cause); ok {
+			return sendDBError(c, dbErr)
 		}
+		return ErrorConflict(c, e.description)
 	}
 
-	return
+	response := Response{Message: e.description}
+	if len(e.errorData) > 0 {
+		errorData := e.errorData
+		response.ErrorData = &errorData
+	}
+
+	return Send(c, int(e.code), response)
 }
 
 func (e *ErrorResponse) setCode(code errorCode) {
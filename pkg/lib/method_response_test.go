@@ -1,6 +1,8 @@
 package lib
 
 import (
+	"errors"
+	"fmt"
 	"net/http/httptest"
 	"reflect"
 	"testing"
@@ -576,3 +578,57 @@ func TestErrorResponse_SendToContext(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorResponse_Error(t *testing.T) {
+	cause := errors.New("record not found")
+	errResp := SetErrorNotFound("Booking not found").WithCause(cause)
+
+	var err error = errResp
+	utils.AssertEqual(t, "Booking not found", err.Error(), "description")
+	utils.AssertEqual(t, true, errors.Is(err, cause), "unwrap cause")
+
+	target := ErrorResponse{}
+	utils.AssertEqual(t, true, errors.As(fmt.Errorf("find booking: %w", err), &target), "errors as")
+	utils.AssertEqual(t, 404, target.Code(), "wrapped code")
+
+	utils.AssertEqual(t, "record not found", SetErrorNotFound().WithCause(cause).Error(), "cause message")
+	utils.AssertEqual(t, errorMessageTooManyRequests, SetErrorTooManyRequests().Error(), "default message")
+	utils.AssertEqual(t, false, SetErrorNotFound().WithCause(cause).IsEmpty(), "not empty")
+
+	errResp = SetErrorUnprocessableEntity("Invalid booking").WithErrorData(ErrorData{Name: "end_date", Path: "end_date", Validator: "gtefield"})
+	utils.AssertEqual(t, 1, len(errResp.ErrorData()), "error data")
+	utils.AssertEqual(t, 0, len(SetErrorUnprocessableEntity().ErrorData()), "immutable copy")
+}
+
+func TestErrorResponse_SendToContextCodes(t *testing.T) {
+	tests := []struct {
+		errResp ErrorResponse
+		code    int
+	}{
+		{SetErrorForbidden(), 403},
+		{SetErrorNotAllowed(), 405},
+		{SetErrorUnprocessableEntity(), 422},
+		{SetErrorTooManyRequests(), 429},
+		{SetErrorServiceUnavailable(), 503},
+	}
+	for _, tt := range tests {
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			return tt.errResp.SendToContext(c)
+		})
+
+		response, body, err := GetTest(app, "/", nil)
+		utils.AssertEqual(t, nil, err, "sending request")
+		utils.AssertEqual(t, tt.code, response.StatusCode, "status code")
+		utils.AssertEqual(t, errorMessages[errorCode(tt.code)], body["message"], "default message")
+	}
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return SetErrorUnprocessableEntity("Invalid booking").WithErrorData(ErrorData{Name: "end_date", Path: "end_date", Validator: "gtefield"}).SendToContext(c)
+	})
+	response, body, _ := GetTest(app, "/", nil)
+	utils.AssertEqual(t, 422, response.StatusCode, "status code")
+	errs, _ := body["error_data"].([]interface{})
+	utils.AssertEqual(t, 1, len(errs), "error data")
+}
@@ -0,0 +1,196 @@
+package lib
+
+import (
+	"reflect"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize int64 = 10
+	maxPageSize     int64 = 1000
+)
+
+// ListQuery list endpoint query parameters
+type ListQuery struct {
+	Page    int64  `json:"page" query:"page"`       // current page, start from zero
+	Size    int64  `json:"size" query:"size"`       // size per page, default `10`
+	Sort    string `json:"sort" query:"sort"`       // sort fields, example: -created_at,name
+	Filters string `json:"filters" query:"filters"` // filter DSL, see CustomFilters
+	Search  string `json:"search" query:"search"`   // search term
+	Columns string `json:"columns" query:"columns"` // search columns, example: ["trx_id","id"]
+}
+
+// GetListQuery get list query parameters from http request
+func GetListQuery(c *fiber.Ctx) ListQuery {
+	query := ListQuery{
+		Page:    int64(c.QueryInt("page", 0)),
+		Size:    int64(c.QueryInt("size", int(defaultPageSize))),
+		Sort:    c.Query("sort"),
+		Filters: c.Query("filters"),
+		Search:  c.Query("search"),
+		Columns: c.Query("columns"),
+	}
+	query.normalize()
+
+	return query
+}
+
+func (q *ListQuery) normalize() {
+	if q.Page < 0 {
+		q.Page = 0
+	}
+	if q.Size <= 0 {
+		q.Size = defaultPageSize
+	}
+	if q.Size > maxPageSize {
+		q.Size = maxPageSize
+	}
+}
+
+// ListScope gorm scope to apply filters, search and sort of list query,
+// parse errors are added to the gorm error, sort fields are validated by ParseSort,
+// without schema filter fields and search columns must be plain identifiers, ex: status, agent__name, bookings.status
+//
+//	=> Example
+//	db.Model(&model.Booking{}).Scopes(lib.ListScope(query)).Find(&bookings)
+func ListScope(query ListQuery, schema ...QuerySchema) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db, err := applyListFilters(db, query, schema...)
+		if nil != err {
+			db.AddError(err)
+			return db
+		}
+
+		orderBy, err := listOrderBy(db, query.Sort, schema...)
+		if nil != err {
+			db.AddError(err)
+			return db
+		}
+		if orderBy != "" {
+			db = db.Order(orderBy)
+		}
+
+		return db
+	}
+}
+
+func applyListFilters(db *gorm.DB, query ListQuery, schema ...QuerySchema) (*gorm.DB, error) {
+	var (
+		filters, search            string
+		filterParams, searchParams []interface{}
+		err                        error
+	)
+	if len(schema) > 0 {
+		filters, filterParams, search, searchParams, err = schema[0].WithDB(db).CustomFilters(query.Filters, query.Search, query.Columns)
+	} else {
+		filters, filterParams, search, searchParams, err = parseCustomFilters(query.Filters, query.Search, query.Columns, nil, DialectOf(db))
+	}
+	if nil != err {
+		return db, err
+	}
+
+	// gorm wraps expressions containing OR with parentheses when combined with other conditions
+	if filters != "" {
+		db = db.Where(filters, filterParams...)
+	}
+	if search != "" {
+		db = db.Where(search, searchParams...)
+	}
+
+	return db, nil
+}
+
+// listOrderBy validated ORDER BY clause, only sortable fields are allowed when schema is given
+func listOrderBy(db *gorm.DB, sort string, schema ...QuerySchema) (string, error) {
+	if len(schema) > 0 {
+		return schema[0].WithDB(db).OrderBy(sort)
+	}
+	items, err := ParseSort(sort)
+	if nil != err {
+		return "", err
+	}
+	return items.OrderBy(DialectOf(db)), nil
+}
+
+// Paginate find paginated items by list query, fields are validated like ListScope
+//
+//	=> Example
+//	bookings := []model.Booking{}
+//	page, err := lib.Paginate(db, &model.Booking{}, &bookings, lib.GetListQuery(c))
+//	if nil != err {
+//		return lib.ErrorBadRequest(c, err)
+//	}
+//	return lib.OK(c, page)
+func Paginate(db *gorm.DB, model interface{}, items interface{}, query ListQuery, schema ...QuerySchema) (Page, error) {
+	query.normalize()
+	page := NewPage(items, query.Page, query.Size, 0)
+
+	tx, err := applyListFilters(db.Model(model), query, schema...)
+	if nil != err {
+		return page, err
+	}
+	orderBy, err := listOrderBy(db, query.Sort, schema...)
+	if nil != err {
+		return page, err
+	}
+
+	var total int64
+	if err := tx.Session(&gorm.Session{}).Count(&total).Error; nil != err {
+		return page, err
+	}
+
+	find := tx.Session(&gorm.Session{})
+	if orderBy != "" {
+		find = find.Order(orderBy)
+	}
+	if err := find.Offset(int(query.Page * query.Size)).Limit(int(query.Size)).Find(items).Error; nil != err {
+		return page, err
+	}
+
+	return NewPage(items, query.Page, query.Size, total), nil
+}
+
+// NewPage create pagination model
+func NewPage(items interface{}, page, size, total int64) Page {
+	if size <= 0 {
+		size = defaultPageSize
+	}
+
+	totalPages := (total + size - 1) / size
+	maxPage := totalPages - 1
+	if maxPage < 0 {
+		maxPage = 0
+	}
+
+	return Page{
+		Items:      items,
+		Page:       page,
+		Size:       size,
+		MaxPage:    maxPage,
+		TotalPages: totalPages,
+		Total:      total,
+		First:      page == 0,
+		Last:       page >= maxPage,
+		Visible:    countItems(items),
+	}
+}
+
+// countItems count length of slice or pointer to slice
+func countItems(items interface{}) int64 {
+	value := reflect.ValueOf(items)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return 0
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		return int64(value.Len())
+	}
+
+	return 0
+}
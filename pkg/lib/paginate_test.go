@@ -0,0 +1,126 @@
+package lib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils/tests"
+)
+
+type paginateSample struct {
+	ID     string
+	Status string
+	Amount float64
+}
+
+// sqlRecorder gorm logger which records generated queries
+type sqlRecorder struct {
+	queries []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.queries = append(r.queries, sql)
+}
+
+func dryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	recorder := &sqlRecorder{}
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{DryRun: true, Logger: recorder})
+	utils.AssertEqual(t, nil, err, "open dry run database")
+	// dummy dialector does not register the query builder callbacks
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return db, recorder
+}
+
+func TestPaginate(t *testing.T) {
+	db, recorder := dryRunDB(t)
+
+	items := []paginateSample{}
+	page, err := Paginate(db, &paginateSample{}, &items, ListQuery{
+		Page:    2,
+		Size:    5,
+		Sort:    "-amount",
+		Filters: `[["status","=","paid"],["OR"],["status","=","due"]]`,
+		Search:  "john",
+		Columns: `["id"]`,
+	})
+	utils.AssertEqual(t, nil, err, "paginate")
+	utils.AssertEqual(t, int64(2), page.Page, "page")
+	utils.AssertEqual(t, int64(5), page.Size, "size")
+	utils.AssertEqual(t, false, page.First, "first")
+	utils.AssertEqual(t, 2, len(recorder.queries), "count and find queries")
+	utils.AssertEqual(t, "SELECT count(*) FROM `paginate_samples` WHERE (status = \"paid\" OR status = \"due\") AND id LIKE \"%john%\"", recorder.queries[0], "count query")
+	utils.AssertEqual(t, "SELECT * FROM `paginate_samples` WHERE (status = \"paid\" OR status = \"due\") AND id LIKE \"%john%\" ORDER BY amount DESC LIMIT 5 OFFSET 10", recorder.queries[1], "find query")
+
+	// invalid filters are returned before querying database
+	_, err = Paginate(db, &paginateSample{}, &items, ListQuery{Filters: `["status","IN","paid"]`})
+	_, ok := err.(FilterErrors)
+	utils.AssertEqual(t, true, ok, "invalid filters")
+
+	// field names and search columns become SQL text without schema, only identifiers are accepted
+	_, err = Paginate(db, &paginateSample{}, &items, ListQuery{Filters: `[["1=1) OR (1","=",1]]`})
+	errs, _ := err.(FilterErrors)
+	utils.AssertEqual(t, 1, len(errs), "injected field name")
+	utils.AssertEqual(t, "filters[0][0]", errs[0].Path(), "injected field path")
+	_, err = Paginate(db, &paginateSample{}, &items, ListQuery{Search: "x", Columns: `["id","name) OR 1=1 --"]`})
+	errs, _ = err.(FilterErrors)
+	utils.AssertEqual(t, 1, len(errs), "injected search column")
+	_, err = Paginate(db, &paginateSample{}, &items, ListQuery{Filters: `[["agent__name","=","a"],["AND"],["bookings.status","=","b"]]`})
+	utils.AssertEqual(t, nil, err, "relation and table fields")
+
+	// invalid sort fields are rejected
+	_, err = Paginate(db, &paginateSample{}, &items, ListQuery{Sort: "amount;DELETE FROM users"})
+	_, ok = err.(FilterErrors)
+	utils.AssertEqual(t, true, ok, "invalid sort")
+
+	// scope
+	recorder.queries = []string{}
+	err = db.Model(&paginateSample{}).Scopes(ListScope(ListQuery{Filters: `["amount",">",10]`, Sort: "status"})).Find(&items).Error
+	utils.AssertEqual(t, nil, err, "list scope")
+	utils.AssertEqual(t, "SELECT * FROM `paginate_samples` WHERE amount > \"10\" ORDER BY status ASC", recorder.queries[0], "list scope query")
+
+	err = db.Model(&paginateSample{}).Scopes(ListScope(ListQuery{Filters: `["amount",">",[1]]`})).Find(&items).Error
+	utils.AssertEqual(t, true, nil != err, "list scope error")
+}
+
+func TestNewPage(t *testing.T) {
+	items := []int{1, 2, 3}
+	page := NewPage(&items, 0, 3, 7)
+	utils.AssertEqual(t, int64(3), page.TotalPages, "total pages")
+	utils.AssertEqual(t, int64(2), page.MaxPage, "max page")
+	utils.AssertEqual(t, true, page.First, "first")
+	utils.AssertEqual(t, false, page.Last, "last")
+	utils.AssertEqual(t, int64(3), page.Visible, "visible")
+
+	page = NewPage(items, 2, 3, 7)
+	utils.AssertEqual(t, true, page.Last, "last")
+
+	page = NewPage(nil, 0, 0, 0)
+	utils.AssertEqual(t, int64(10), page.Size, "default size")
+	utils.AssertEqual(t, true, page.Last, "empty last")
+	utils.AssertEqual(t, int64(0), page.Visible, "empty visible")
+}
+
+func TestGetListQuery(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(GetListQuery(c))
+	})
+
+	response, body, err := GetTest(app, "/?page=-1&size=5000&sort=-name", nil)
+	utils.AssertEqual(t, nil, err, "sending request")
+	utils.AssertEqual(t, 200, response.StatusCode, "status code")
+	utils.AssertEqual(t, float64(0), body["page"], "page")
+	utils.AssertEqual(t, float64(maxPageSize), body["size"], "size")
+	utils.AssertEqual(t, "-name", body["sort"], "sort")
+}
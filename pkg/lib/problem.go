@@ -0,0 +1,114 @@
+package lib
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEApplicationProblemJSON RFC 7807 problem document content type
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// error response formats of Send and the Error* helpers
+const (
+	ErrorFormatNegotiate = ""         // default, problem document when Accept header prefers application/problem+json
+	ErrorFormatResponse  = "response" // always lib.Response
+	ErrorFormatProblem   = "problem"  // always application/problem+json
+)
+
+// errorFormat current error response format
+var errorFormat = ErrorFormatNegotiate
+
+// SetErrorFormat set error response format globally
+//
+//	lib.SetErrorFormat(lib.ErrorFormatProblem) // public API, every error is a problem document
+func SetErrorFormat(format string) {
+	switch format {
+	case ErrorFormatResponse, ErrorFormatProblem:
+		errorFormat = format
+	default:
+		errorFormat = ErrorFormatNegotiate
+	}
+}
+
+// Problem RFC 7807 problem details document
+type Problem struct {
+	Type       string                 `json:"type" example:"about:blank"`               // problem type URI
+	Title      string                 `json:"title" example:"Bad Request"`              // short summary of the problem type
+	Status     int                    `json:"status" example:"400"`                     // http status
+	Detail     string                 `json:"detail,omitempty" example:"invalid value"` // explanation of this occurrence
+	Instance   string                 `json:"instance,omitempty" example:"/bookings"`   // request URI of this occurrence
+	Errors     []ErrorData            `json:"errors,omitempty"`                         // field errors extension
+	Extensions map[string]interface{} `json:"-"`                                        // additional members
+}
+
+// MarshalJSON merge extension members into the problem document
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	document, err := JSONMarshal(problem(p))
+	if nil != err || len(p.Extensions) == 0 {
+		return document, err
+	}
+
+	members := map[string]interface{}{}
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	if err := JSONUnmarshal(document, &members); nil != err {
+		return nil, err
+	}
+	return JSONMarshal(members)
+}
+
+// NewProblem create problem document from response
+func NewProblem(status int, response Response) Problem {
+	problem := Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+	if problem.Title == "" {
+		problem.Title = response.Message
+	}
+
+	if nil != response.ErrorDescription && *response.ErrorDescription != problem.Title {
+		problem.Detail = *response.ErrorDescription
+	} else if response.Message != problem.Title {
+		problem.Detail = response.Message
+	}
+	if nil != response.ErrorData {
+		problem.Errors = *response.ErrorData
+	}
+
+	return problem
+}
+
+// wantsProblem check whether error response should be a problem document
+func wantsProblem(c *fiber.Ctx) bool {
+	switch errorFormat {
+	case ErrorFormatProblem:
+		return true
+	case ErrorFormatResponse:
+		return false
+	}
+	return c.Accepts(fiber.MIMEApplicationJSON, MIMEApplicationProblemJSON) == MIMEApplicationProblemJSON
+}
+
+// SendProblem send problem document
+func SendProblem(c *fiber.Ctx, problem Problem) error {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Instance == "" {
+		problem.Instance = c.OriginalURL()
+	}
+
+	if err := c.Status(problem.Status).JSON(problem); nil != err {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, MIMEApplicationProblemJSON)
+	return nil
+}
@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestProblemResponse(t *testing.T) {
+	defer SetErrorFormat(ErrorFormatNegotiate)
+
+	app := fiber.New()
+	app.Get("/filters", func(c *fiber.Ctx) error {
+		_, _, _, _, err := ParseCustomFilters(`["status","EQUALS","paid"]`, "", "")
+		return ErrorBadRequest(c, err)
+	})
+	app.Get("/not-found", func(c *fiber.Ctx) error {
+		return ErrorNotFound(c, "Booking not found")
+	})
+	app.Get("/custom", func(c *fiber.Ctx) error {
+		return Send(c, 402, Problem{Type: "https://example.com/probs/credit", Title: "Out of credit", Extensions: map[string]interface{}{"balance": 30, "status": 1}})
+	})
+
+	// legacy response by default
+	response, body, err := GetTest(app, "/not-found", nil)
+	utils.AssertEqual(t, nil, err, "sending request")
+	utils.AssertEqual(t, fiber.MIMEApplicationJSON, response.Header.Get(fiber.HeaderContentType), "legacy content type")
+	utils.AssertEqual(t, "Booking not found", body["message"], "legacy message")
+
+	// negotiated by Accept header
+	headers := map[string]string{fiber.HeaderAccept: MIMEApplicationProblemJSON}
+	response, body, err = GetTest(app, "/filters", headers)
+	utils.AssertEqual(t, nil, err, "sending request")
+	utils.AssertEqual(t, 400, response.StatusCode, "status code")
+	utils.AssertEqual(t, MIMEApplicationProblemJSON, response.Header.Get(fiber.HeaderContentType), "problem content type")
+	utils.AssertEqual(t, "about:blank", body["type"], "type")
+	utils.AssertEqual(t, "Bad Request", body["title"], "title")
+	utils.AssertEqual(t, float64(400), body["status"], "status")
+	utils.AssertEqual(t, "/filters", body["instance"], "instance")
+	errs, _ := body["errors"].([]interface{})
+	utils.AssertEqual(t, 1, len(errs), "errors extension")
+	utils.AssertEqual(t, "filters[1]", errs[0].(map[string]interface{})["path"], "error path")
+
+	// global switch
+	SetErrorFormat(ErrorFormatProblem)
+	response, body, _ = GetTest(app, "/not-found", nil)
+	utils.AssertEqual(t, MIMEApplicationProblemJSON, response.Header.Get(fiber.HeaderContentType), "global problem")
+	utils.AssertEqual(t, "Not Found", body["title"], "global title")
+	utils.AssertEqual(t, "Booking not found", body["detail"], "global detail")
+
+	SetErrorFormat(ErrorFormatResponse)
+	_, body, _ = GetTest(app, "/not-found", headers)
+	utils.AssertEqual(t, "Booking not found", body["message"], "forced response")
+
+	// explicit problem with extensions
+	response, body, _ = GetTest(app, "/custom", nil)
+	utils.AssertEqual(t, 402, response.StatusCode, "custom status")
+	utils.AssertEqual(t, "Out of credit", body["title"], "custom title")
+	utils.AssertEqual(t, float64(30), body["balance"], "extension member")
+	utils.AssertEqual(t, float64(402), body["status"], "standard member wins")
+}
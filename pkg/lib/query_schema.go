@@ -0,0 +1,277 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// query field value types
+const (
+	QueryFieldString   = "string"
+	QueryFieldNumber   = "number"
+	QueryFieldInteger  = "integer"
+	QueryFieldBool     = "bool"
+	QueryFieldUUID     = "uuid"
+	QueryFieldDate     = "date"     // YYYY-MM-DD
+	QueryFieldDateTime = "datetime" // RFC3339 or YYYY-MM-DD hh:mm:ss
+	QueryFieldJSON     = "json"     // json / jsonb document, values are kept as is
+)
+
+// QueryField field which can be used by filters or search query
+type QueryField struct {
+	Column     string // database column expression, default is NormalizeFieldName of the field name
+	Type       string // value type, values are coerced into this type before they become bind parameters
+	Filterable bool   // field can be used in filters
+	Searchable bool   // field can be used in search columns
+	Sortable   bool   // field can be used in sort
+	Nullable   bool   // column can be NULL, it can not be used in cursor pagination sort
+}
+
+// QuerySchema allowed query fields of an endpoint, keyed by API field name
+//
+//	=> Example
+//	schema := lib.QuerySchema{Fields: map[string]lib.QueryField{
+//		"status":      {Type: lib.QueryFieldString, Filterable: true, Searchable: true, Sortable: true},
+//		"amount":      {Type: lib.QueryFieldNumber, Filterable: true},
+//		"agent__name": {Type: lib.QueryFieldString, Searchable: true}, // column: Agent__name
+//	}}
+//	filters, filterParams, search, searchParams, err := schema.CustomFilters(c.Query("filters"), c.Query("search"), c.Query("columns"))
+type QuerySchema struct {
+	Fields  map[string]QueryField
+	Dialect FilterDialect // SQL dialect, set by WithDB or inferred by Paginate
+	Search  SearchOptions // search query behaviour, default matches the whole term
+}
+
+// WithDB set schema dialect based on gorm database driver
+func (s QuerySchema) WithDB(db *gorm.DB) QuerySchema {
+	if s.Dialect == "" {
+		s.Dialect = DialectOf(db)
+	}
+	return s
+}
+
+// ParseFilter parse filter DSL and resolve its fields and values against the schema
+func (s QuerySchema) ParseFilter(jsonParams string) (FilterExpr, error) {
+	return parseFilter(jsonParams, &s)
+}
+
+// ToSQL compile expression parsed by the schema into where clause and its bind parameters
+func (s QuerySchema) ToSQL(expr FilterExpr) (string, []interface{}, error) {
+	return filterCompiler{typed: true, dialect: s.Dialect}.compile(expr)
+}
+
+// CustomFilters schema aware CustomFilters, undeclared fields are rejected
+// and values are coerced into the declared field type.
+// When columnFilter is empty, all searchable fields are searched.
+func (s QuerySchema) CustomFilters(QueryFilters, QuerySearch, columnFilter string) (string, []interface{}, string, []interface{}, error) {
+	return parseCustomFilters(QueryFilters, QuerySearch, columnFilter, &s, s.Dialect)
+}
+
+// Column get column expression of a declared field
+func (s QuerySchema) Column(name string) (string, bool) {
+	field, ok := s.Fields[name]
+	if !ok {
+		return "", false
+	}
+	return field.column(name), true
+}
+
+func (f QueryField) column(name string) string {
+	if f.Column != "" {
+		return f.Column
+	}
+	return NormalizeFieldName(name)
+}
+
+// sqlColumn SQL text of a declared field, the declared Column expression is trusted as is
+// and the default column is quoted by the schema dialect
+func (s QuerySchema) sqlColumn(name string, field QueryField) string {
+	if field.Column != "" {
+		return field.Column
+	}
+	return s.Dialect.Quote(NormalizeFieldName(name))
+}
+
+// resolve replace condition field with its SQL column and coerce its value
+func (s QuerySchema) resolve(p *filterParser, item *FilterItem, name string, position, valuePosition []int) {
+	field, ok := s.Fields[name]
+	if !ok || !field.Filterable {
+		p.fail(append(position, 0), name, "field %s is not filterable", name)
+		return
+	}
+	item.Field = s.sqlColumn(name, field)
+
+	if isTextOperator(item.Operator) && field.Type != "" && field.Type != QueryFieldString {
+		p.fail(append(position, 1), item.Operator, "operator %s is only allowed on string field", item.Operator)
+		return
+	}
+
+	if item.ValueType == "null" {
+		return
+	}
+
+	switch item.Operator {
+	case FilterDateBetween:
+		if field.Type != "" && field.Type != QueryFieldDate && field.Type != QueryFieldDateTime {
+			p.fail(append(position, 1), item.Operator, "operator %s is only allowed on date field", item.Operator)
+			return
+		}
+		item.ValueType = field.Type
+		return
+	case FilterContains:
+		if _, isObject := item.Value.(map[string]interface{}); isObject && field.Type != "" && field.Type != QueryFieldJSON {
+			p.fail(valuePosition, item.Value, "object value is only allowed on json field")
+			return
+		}
+	}
+
+	if field.Type != "" {
+		item.ValueType = field.Type
+	}
+	if field.Type == QueryFieldJSON || item.ValueType == "object" {
+		return
+	}
+
+	if values, isArray := item.Value.([]interface{}); isArray {
+		coerced := []interface{}{}
+		for i, value := range values {
+			v, err := field.Coerce(value)
+			if nil != err {
+				p.fail(append(valuePosition, i), value, "%s", err.Error())
+				continue
+			}
+			coerced = append(coerced, v)
+		}
+		item.Value = coerced
+		return
+	}
+
+	v, err := field.Coerce(item.Value)
+	if nil != err {
+		p.fail(valuePosition, item.Value, "%s", err.Error())
+		return
+	}
+	item.Value = v
+}
+
+// isTextOperator check whether operator only applies to string values
+func isTextOperator(operator string) bool {
+	switch operator {
+	case FilterStartsWith, FilterEndsWith, FilterIsEmpty, FilterIsNotEmpty:
+		return true
+	}
+	return strings.Contains(operator, "LIKE")
+}
+
+// searchColumns resolve search columns into column expressions
+func (s QuerySchema) searchColumns(names []string) ([]string, error) {
+	columns := []string{}
+	if len(names) == 0 {
+		for name, field := range s.Fields {
+			if field.Searchable {
+				columns = append(columns, s.sqlColumn(name, field))
+			}
+		}
+		sort.Strings(columns)
+		return columns, nil
+	}
+
+	errs := FilterErrors{}
+	for i, name := range names {
+		field, ok := s.Fields[name]
+		if !ok || !field.Searchable {
+			errs = append(errs, FilterError{Name: searchColumnParamName, Position: []int{i}, Value: name, Message: fmt.Sprintf("field %s is not searchable", name)})
+			continue
+		}
+		columns = append(columns, s.sqlColumn(name, field))
+	}
+
+	if len(errs) > 0 {
+		return columns, errs
+	}
+
+	return columns, nil
+}
+
+// Coerce convert filter value into the declared field type
+//
+//gocyclo:ignore
+func (f QueryField) Coerce(value interface{}) (interface{}, error) {
+	switch f.Type {
+	case "", QueryFieldJSON:
+		return value, nil
+	case QueryFieldString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	case QueryFieldNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); nil == err {
+				return n, nil
+			}
+		}
+	case QueryFieldInteger:
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); nil == err {
+				return n, nil
+			}
+		}
+	case QueryFieldBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); nil == err {
+				return b, nil
+			}
+		case float64:
+			if v == 0 || v == 1 {
+				return v == 1, nil
+			}
+		}
+	case QueryFieldUUID:
+		if v, ok := value.(string); ok {
+			if id, err := uuid.Parse(strings.TrimSpace(v)); nil == err {
+				return id, nil
+			}
+		}
+	case QueryFieldDate:
+		if v, ok := value.(string); ok {
+			if t, err := time.Parse("2006-01-02", strings.TrimSpace(v)); nil == err {
+				return t, nil
+			}
+		}
+	case QueryFieldDateTime:
+		if v, ok := value.(string); ok {
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+				if t, err := time.Parse(layout, strings.TrimSpace(v)); nil == err {
+					return t, nil
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown field type %s", f.Type)
+	}
+
+	return nil, fmt.Errorf("value %v is not a valid %s", value, f.Type)
+}
@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+)
+
+func sampleQuerySchema() QuerySchema {
+	return QuerySchema{Fields: map[string]QueryField{
+		"id":           {Type: QueryFieldUUID, Filterable: true},
+		"status":       {Type: QueryFieldString, Filterable: true, Searchable: true},
+		"amount":       {Type: QueryFieldNumber, Filterable: true},
+		"pax":          {Type: QueryFieldInteger, Filterable: true},
+		"is_active":    {Type: QueryFieldBool, Filterable: true},
+		"created_at":   {Column: "bookings.created_at", Type: QueryFieldDateTime, Filterable: true},
+		"agent__name":  {Type: QueryFieldString, Filterable: true, Searchable: true},
+		"booking_code": {Column: "bookings.code", Searchable: true},
+	}}
+}
+
+func TestQuerySchemaCustomFilters(t *testing.T) {
+	schema := sampleQuerySchema()
+	id := uuid.New()
+
+	filters, filterParams, search, searchParams, err := schema.CustomFilters(
+		`[["id","=","`+id.String()+`"],["AND"],["amount",">=","10.5"],["AND"],["pax","IN",[1,"2"]],["AND"],["is_active","=","true"]]`,
+		"john",
+		`["agent__name","booking_code"]`,
+	)
+	utils.AssertEqual(t, nil, err, "schema custom filters")
+	utils.AssertEqual(t, "id = ? AND amount >= ? AND pax IN ? AND is_active = ?", filters, "filters")
+	utils.AssertEqual(t, []interface{}{id, 10.5, []interface{}{int64(1), int64(2)}, true}, filterParams, "typed filter params")
+	utils.AssertEqual(t, "Agent__name LIKE ? OR bookings.code LIKE ?", search, "search")
+	utils.AssertEqual(t, 2, len(searchParams), "search params")
+
+	// column expression and datetime value
+	filters, filterParams, _, _, err = schema.CustomFilters(`["created_at","BETWEEN",["2023-01-01 00:00:00","2023-01-31T23:59:59Z"]]`, "", "")
+	utils.AssertEqual(t, nil, err, "datetime filter")
+	utils.AssertEqual(t, "bookings.created_at BETWEEN ? AND ?", filters, "datetime filter query")
+	utils.AssertEqual(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), filterParams[0], "datetime filter params")
+
+	// all searchable fields are used when search columns are empty
+	_, _, search, _, err = schema.CustomFilters("", "john", "")
+	utils.AssertEqual(t, nil, err, "default search columns")
+	utils.AssertEqual(t, "Agent__name LIKE ? OR bookings.code LIKE ? OR status LIKE ?", search, "default search columns")
+}
+
+func TestQuerySchemaRejectsUndeclaredFields(t *testing.T) {
+	schema := sampleQuerySchema()
+
+	_, _, _, _, err := schema.CustomFilters(`[["status","=","paid"],["OR"],["password","=","secret"]]`, "", "")
+	errs, ok := err.(FilterErrors)
+	utils.AssertEqual(t, true, ok, "undeclared filter field")
+	utils.AssertEqual(t, "filters[2][0]", errs[0].Path(), "undeclared filter field path")
+
+	_, _, _, _, err = schema.CustomFilters(`["1=1; --","=",1]`, "", "")
+	utils.AssertEqual(t, true, nil != err, "injected filter field")
+
+	// searchable only field can not be filtered
+	_, _, _, _, err = schema.CustomFilters(`["booking_code","=","ABC"]`, "", "")
+	utils.AssertEqual(t, true, nil != err, "search only field")
+
+	_, _, _, _, err = schema.CustomFilters("", "john", `["status","id) OR 1=1 OR (id"]`)
+	errs, _ = err.(FilterErrors)
+	utils.AssertEqual(t, "columns[1]", errs[0].Path(), "undeclared search column")
+
+	// invalid typed values
+	cases := []string{
+		`["id","=","not-uuid"]`,
+		`["amount","=","ten"]`,
+		`["pax","=",1.5]`,
+		`["pax","IN",[1,"two"]]`,
+		`["is_active","=","maybe"]`,
+		`["created_at","=","yesterday"]`,
+		`["amount","LIKE","1"]`,
+	}
+	for _, c := range cases {
+		_, err = schema.ParseFilter(c)
+		utils.AssertEqual(t, true, nil != err, c)
+	}
+}
+
+func TestQueryFieldCoerce(t *testing.T) {
+	value, err := QueryField{Type: QueryFieldString}.Coerce(10.0)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "10", value)
+
+	value, err = QueryField{Type: QueryFieldDate}.Coerce("2023-02-01")
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC), value)
+
+	value, err = QueryField{Type: QueryFieldBool}.Coerce(1.0)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, value)
+
+	_, err = QueryField{Type: "unknown"}.Coerce("a")
+	utils.AssertEqual(t, true, nil != err)
+
+	column, ok := sampleQuerySchema().Column("agent__name")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, "Agent__name", column)
+}
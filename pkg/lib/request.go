@@ -1,14 +1,18 @@
 package lib
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
 	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/iancoleman/strcase"
 	"github.com/spf13/viper"
+	"golang.org/x/text/language"
 )
 
 // VALIDATOR validate request body
@@ -20,17 +24,68 @@ const (
 	customPhoneNumber           = `^\+[1-9]\d{1,31}$` // using validator e164 with custom length 32 characters (default e164 is 15 characters)
 )
 
+// embeddedFieldName validator name of embedded struct without json name, dropped from error paths
+const embeddedFieldName = "-"
+
+// languagePattern valid LANGUAGE config value
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}([-_][a-z0-9]+)*$`)
+
+// typeQualifierPattern package qualifier of a type name
+var typeQualifierPattern = regexp.MustCompile(`[A-Za-z0-9_]+\.`)
+
 func init() {
 	VALIDATOR.RegisterValidation("specialcharacter", customValidator(specialcharacter))
 	VALIDATOR.RegisterValidation("alphanumunicodespace", customValidator(alphaNumUniCodeSpacePattern))
 	VALIDATOR.RegisterValidation("customphonenumber", customValidator(customPhoneNumber))
+	VALIDATOR.RegisterTagNameFunc(jsonFieldName)
+}
+
+// jsonFieldName field name of validation errors, json name or snake case of the struct field name
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch {
+	case name == "-":
+		return strcase.ToSnake(field.Name)
+	case name != "":
+		return name
+	case field.Anonymous:
+		return embeddedFieldName
+	}
+	return strcase.ToSnake(field.Name)
+}
+
+// validationField name, path and type of a validation error as sent by the client
+//
+//	Booking.passengers[2].birth_date -> birth_date, passengers[2].birth_date, DateTime
+func validationField(err validator.FieldError) (name, path, fieldType string) {
+	segments := []string{}
+	for i, segment := range strings.Split(err.Namespace(), ".") {
+		if i == 0 || segment == embeddedFieldName {
+			continue // root struct and embedded structs are not part of the json document
+		}
+		segments = append(segments, segment)
+	}
+	path = strings.Join(segments, ".")
+
+	name = err.Field()
+	if index := strings.Index(name, "["); index > 0 {
+		name = name[:index]
+	}
+	if name == embeddedFieldName || path == "" {
+		name = strcase.ToSnake(err.StructField())
+		path = name
+	}
+
+	fieldType = typeQualifierPattern.ReplaceAllString(strings.ReplaceAll(fmt.Sprint(err.Type()), "*", ""), "")
+
+	return name, path, fieldType
 }
 
+// customValidator regex validator, the pattern is compiled once
 func customValidator(pattern string) validator.Func {
+	re := regexp.MustCompile(pattern)
 	return func(f validator.FieldLevel) bool {
-		str := f.Field().String()
-		re := regexp.MustCompile(pattern)
-		return re.MatchString(str)
+		return re.MatchString(f.Field().String())
 	}
 }
 
@@ -39,8 +94,11 @@ type HTTPClient interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
-// GetXUserID provide user id by http headers
+// GetXUserID provide user id by http headers, the identity parsed by IdentityMiddleware is used when available
 func GetXUserID(c *fiber.Ctx) *uuid.UUID {
+	if identity, ok := c.Locals(identityLocal).(*Identity); ok {
+		return identity.UserID
+	}
 	id := string(c.Request().Header.Peek("x-user-id"))
 	if id != "" {
 		if current, err := uuid.Parse(id); nil == err {
@@ -53,6 +111,9 @@ func GetXUserID(c *fiber.Ctx) *uuid.UUID {
 
 // GetXAgentID provide user id by http headers
 func GetXAgentID(c *fiber.Ctx) *uuid.UUID {
+	if identity, ok := c.Locals(identityLocal).(*Identity); ok {
+		return identity.AgentID
+	}
 	id := string(c.Request().Header.Peek("x-agent-id"))
 	if id != "" {
 		if current, err := uuid.Parse(id); nil == err {
@@ -69,6 +130,9 @@ func GetXAgentID(c *fiber.Ctx) *uuid.UUID {
 
 // GetXCorporateID provide corporate id by http headers
 func GetXCorporateID(c *fiber.Ctx) *uuid.UUID {
+	if identity, ok := c.Locals(identityLocal).(*Identity); ok {
+		return identity.CorporateID
+	}
 	id := string(c.Request().Header.Peek("x-corporate-id"))
 	if id != "" {
 		if current, err := uuid.Parse(id); nil == err {
@@ -79,37 +143,76 @@ func GetXCorporateID(c *fiber.Ctx) *uuid.UUID {
 	return nil
 }
 
-// GetLanguage get language by http header Accept-Language
+// GetLanguage negotiate language of http header Accept-Language by quality weights,
+// the language is matched against LANGUAGE_SUPPORTED config (comma separated BCP 47 tags, example: en,id,pt-BR),
+// without supported languages the base language of the preferred tag is used, LANGUAGE config or en is the fallback
 func GetLanguage(c *fiber.Ctx) string {
-	lang := viper.GetString("LANGUAGE")
-	acceptLanguage := string(c.Request().Header.Peek("accept-language"))
-	if acceptLanguage != "" && len(acceptLanguage) >= 2 {
-		lang = acceptLanguage[0:2]
-		// TODO: check to database if database exists, if not return to fallback language ...
+	fallback := strings.ToLower(viper.GetString("LANGUAGE"))
+	if !languagePattern.MatchString(fallback) {
+		fallback = "en"
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+	if nil != err || len(tags) == 0 {
+		return fallback
 	}
 
-	lang = strings.ToLower(lang)
-	if ok, _ := regexp.Match("^[a-z]", []byte(lang)); !ok || len(lang) < 2 {
-		lang = "en"
+	supported := supportedLanguages()
+	if len(supported) == 0 {
+		for _, tag := range tags {
+			base, confidence := tag.Base()
+			if confidence != language.No && base.String() != "und" && base.String() != "mul" { // "mul" is the * wildcard
+				return strings.ToLower(base.String())
+			}
+		}
+		return fallback
+	}
+
+	supportedTags := []language.Tag{}
+	for _, lang := range supported {
+		supportedTags = append(supportedTags, language.Make(lang))
+	}
+	_, index, confidence := language.NewMatcher(supportedTags).Match(tags...)
+	if confidence == language.No {
+		return fallback
 	}
 
-	return lang
+	return strings.ToLower(supported[index])
 }
 
-// BodyParser with validation
+// supportedLanguages valid languages of LANGUAGE_SUPPORTED config
+func supportedLanguages() []string {
+	supported := []string{}
+	for _, lang := range strings.FieldsFunc(viper.GetString("LANGUAGE_SUPPORTED"), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if _, err := language.Parse(lang); nil == err {
+			supported = append(supported, lang)
+		}
+	}
+	return supported
+}
+
+// BodyParser with sanitization and validation, see Bind for strict binding of params, query, headers and body
 func BodyParser(c *fiber.Ctx, payload interface{}) error {
 	if err := c.BodyParser(payload); nil != err {
 		return err
 	}
+	if err := Sanitize(payload); nil != err {
+		return err
+	}
 
 	return VALIDATOR.Struct(payload)
 }
 
-// QueryParser with validation
+// QueryParser with sanitization and validation
 func QueryParser(c *fiber.Ctx, payload interface{}) error {
 	if err := c.QueryParser(payload); nil != err {
 		return err
 	}
+	if err := Sanitize(payload); nil != err {
+		return err
+	}
 
 	return VALIDATOR.Struct(payload)
 }
\ No newline at end of file
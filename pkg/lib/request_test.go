@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
@@ -238,3 +239,74 @@ func TestQueryParser(t *testing.T) {
 	utils.AssertEqual(t, nil, err)
 	utils.AssertEqual(t, 200, res.StatusCode)
 }
+
+func TestValidationErrorPath(t *testing.T) {
+	type base struct {
+		Code string `json:"code" validate:"required"`
+	}
+	type passenger struct {
+		FullName  string     `json:"full_name" validate:"required"`
+		BirthDate *time.Time `json:"birth_date" validate:"required"`
+	}
+	type booking struct {
+		base
+		PaxList    []passenger `json:"pax_list" validate:"required,dive"`
+		ContactID  *uuid.UUID  `json:"contact_id,omitempty" validate:"required"`
+		TotalPrice float64     `validate:"gte=1"`
+	}
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return ErrorBadRequest(c, VALIDATOR.Struct(booking{
+			PaxList: []passenger{{FullName: "John", BirthDate: &time.Time{}}, {FullName: "Jane", BirthDate: &time.Time{}}, {}},
+		}))
+	})
+
+	_, body, err := GetTest(app, "/", nil)
+	utils.AssertEqual(t, nil, err, "sending request")
+	errs, _ := body["error_data"].([]interface{})
+	utils.AssertEqual(t, 5, len(errs), "error data")
+
+	expected := [][]string{
+		{"code", "code", "string"},
+		{"full_name", "pax_list[2].full_name", "string"},
+		{"birth_date", "pax_list[2].birth_date", "Time"},
+		{"contact_id", "contact_id", "UUID"},
+		{"total_price", "total_price", "float64"},
+	}
+	for i, e := range expected {
+		data := errs[i].(map[string]interface{})
+		utils.AssertEqual(t, e[0], data["name"], "name")
+		utils.AssertEqual(t, e[1], data["path"], "path")
+		utils.AssertEqual(t, e[2], data["type"], "type")
+	}
+}
+
+func TestGetLanguageNegotiation(t *testing.T) {
+	defer viper.Set("LANGUAGE_SUPPORTED", nil)
+	defer viper.Set("LANGUAGE", nil)
+
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(GetLanguage(c))
+	})
+	negotiate := func(acceptLanguage string) string {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Add("Accept-Language", acceptLanguage)
+		response, err := app.Test(request, 500)
+		utils.AssertEqual(t, nil, err, "Sending request")
+		bte, _ := ioutil.ReadAll(response.Body)
+		return string(bte)
+	}
+
+	utils.AssertEqual(t, "ja", negotiate("en-US;q=0.5, ja"), "quality weight")
+	utils.AssertEqual(t, "en", negotiate("*"), "wildcard only")
+
+	viper.Set("LANGUAGE", "id")
+	viper.Set("LANGUAGE_SUPPORTED", "en, id,pt-BR")
+	utils.AssertEqual(t, "id", negotiate("fr-CH, fr;q=0.9, id;q=0.8"), "first supported language")
+	utils.AssertEqual(t, "en", negotiate("id;q=0.1, en-GB;q=0.9"), "region subtag")
+	utils.AssertEqual(t, "pt-br", negotiate("pt"), "configured tag")
+	utils.AssertEqual(t, "id", negotiate("de"), "unsupported language")
+	utils.AssertEqual(t, "id", negotiate(""), "missing header")
+}
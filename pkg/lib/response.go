@@ -1,14 +1,12 @@
 package lib
 
 import (
-	"fmt"
-	"regexp"
+	"errors"
+	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/iancoleman/strcase"
 )
 
 // Response http response
@@ -31,39 +29,23 @@ type ErrorData struct {
 	Message   string      `json:"message,omitempty" example:"invalid value"`               // Field message
 }
 
-type sqlPatterns struct {
-	driver    string
-	pattern   string
-	validator string
-}
-
-var patterns []sqlPatterns = []sqlPatterns{
-	{driver: "postgres", pattern: `ERROR:.*duplicate key.*"([^"]+)".*`, validator: "unique"},
-	{driver: "mysql", pattern: `Duplicate entry.*for key '([^']+)`, validator: "unique"},
-	{driver: "sqlite", pattern: `UNIQUE.*:\s*(.*)`, validator: "unique"},
-	{driver: "postgres", pattern: `ERROR: null.*"([^"]+)".*`, validator: "required"},
-	{driver: "sqlite", pattern: `.*NOT NULL.*:\s*(.*)`, validator: "required"},
-}
-
-var validateMessages map[string]string = map[string]string{
-	"required":         "%v is required %v",
-	"gte":              "%v must be greater than or equal %v",
-	"gt":               "%v must be greater than %v",
-	"lte":              "%v must be less than or equal %v",
-	"lt":               "%v must be less than %v",
-	"unique":           "%v already exists %v",
-	"specialcharacter": "%v must use a valid alphanumeric character only %v",
-	"email":            "%v format is invalid %v",
-	"date":             "%v format is invalid %v",
-	"numeric":          "%v must be a number %v",
+// ErrorDataProvider error which carries its own field error details
+type ErrorDataProvider interface {
+	ErrorData() []ErrorData
 }
 
 // Send response
+//
+//	error responses (status >= 400) can be sent as application/problem+json documents, see SetErrorFormat
 func Send(c *fiber.Ctx, status int, responses ...interface{}) error {
 	response := Response{
 		Status: status,
 	}
 	for i := 0; i < len(responses); i++ {
+		if e, ok := responses[i].(Problem); ok {
+			e.Status = status
+			return SendProblem(c, e)
+		}
 		if e, ok := responses[i].(Response); ok {
 			response = e
 			response.Status = status
@@ -77,23 +59,10 @@ func Send(c *fiber.Ctx, status int, responses ...interface{}) error {
 				response.ErrorDescription = Strptr("Request body does not meet the requirements")
 				errorDetails := []ErrorData{}
 				for _, err := range errs {
-					fields := strings.Split(err.StructNamespace(), ".")
-					field := strcase.ToSnake(err.Field())
-					if len(fields) > 1 {
-						names := []string{}
-						for _, i := range fields[1:] {
-							f := strcase.ToSnake(i)
-							names = append(names, f)
-						}
-						field = strings.Join(names, ".")
-					}
-					fieldType := strings.ReplaceAll(fmt.Sprint(err.Type()), "*", "")
-					regex := regexp.MustCompile(`.*\.([^\.]+)$`)
-					fieldType = regex.ReplaceAllString(fieldType, "$1")
+					name, path, fieldType := validationField(err)
 					errorData := ErrorData{
-						Name: strcase.ToSnake(err.Field()),
-						// Namespace: err.Namespace(),
-						Path:      field,
+						Name:      name,
+						Path:      path,
 						Type:      fieldType,
 						Value:     err.Value(),
 						Validator: err.Tag(),
@@ -105,15 +74,17 @@ func Send(c *fiber.Ctx, status int, responses ...interface{}) error {
 						errorData.Criteria = err.Param()
 					}
 
-					errorData.Message = fmt.Sprintf("Invalid data %v", errorData.Name) //default message if validation translation not exists
-					if m, ok := validateMessages[errorData.Validator]; ok {
-						errorData.Message = fmt.Sprintf(m, errorData.Name, errorData.Criteria)
-					}
+					errorData.Message = ValidationMessage(GetLanguage(c), errorData)
 					errorDetails = append(errorDetails, errorData)
 				}
 				response.ErrorData = &errorDetails
 				break
 			}
+			if errs, ok := e.(ErrorDataProvider); ok {
+				errorDetails := errs.ErrorData()
+				response.ErrorData = &errorDetails
+				break
+			}
 		} else if e, ok := responses[i].(string); ok {
 			response.Message = e
 		}
@@ -123,6 +94,10 @@ func Send(c *fiber.Ctx, status int, responses ...interface{}) error {
 		response.Message = *response.ErrorDescription
 	}
 
+	if status >= 400 && wantsProblem(c) {
+		return SendProblem(c, NewProblem(status, response))
+	}
+
 	return c.Status(status).JSON(response)
 }
 
@@ -141,6 +116,15 @@ func ErrorBadRequest(c *fiber.Ctx, message ...interface{}) error {
 	return Send(c, 400, message[0])
 }
 
+// ErrorForbidden send http 403 forbidden
+func ErrorForbidden(c *fiber.Ctx, message ...string) error {
+	if len(message) == 0 {
+		message = append(message, "Forbidden")
+	}
+
+	return Send(c, 403, message[0])
+}
+
 // ErrorNotFound send http 404 not found
 func ErrorNotFound(c *fiber.Ctx, message ...string) error {
 	if len(message) == 0 {
@@ -167,6 +151,26 @@ func ErrorGone(c *fiber.Ctx, message ...string) error {
 	return Send(c, 410, message[0])
 }
 
+// ErrorUnprocessableEntity send http 422 unprocessable entity
+//
+//	message can contains string or error or nothing
+func ErrorUnprocessableEntity(c *fiber.Ctx, message ...interface{}) error {
+	if len(message) == 0 {
+		message = append(message, "Unprocessable entity")
+	}
+
+	return Send(c, 422, message[0])
+}
+
+// ErrorTooManyRequests send http 429 too many requests
+func ErrorTooManyRequests(c *fiber.Ctx, message ...string) error {
+	if len(message) == 0 {
+		message = append(message, "Too many requests")
+	}
+
+	return Send(c, 429, message[0])
+}
+
 // ErrorInternal send http 500 internal server error
 func ErrorInternal(c *fiber.Ctx, message ...string) error {
 	if len(message) == 0 {
@@ -175,6 +179,15 @@ func ErrorInternal(c *fiber.Ctx, message ...string) error {
 	return Send(c, 500, message[0])
 }
 
+// ErrorServiceUnavailable send http 503 service unavailable
+func ErrorServiceUnavailable(c *fiber.Ctx, message ...string) error {
+	if len(message) == 0 {
+		message = append(message, "Service unavailable")
+	}
+
+	return Send(c, 503, message[0])
+}
+
 // ErrorServerOverload send http 503 internal server error
 func ErrorServerOverload(c *fiber.Ctx, message ...string) error {
 	if len(message) == 0 {
@@ -191,6 +204,7 @@ func ErrorServerOverload(c *fiber.Ctx, message ...string) error {
 //	lib.ErrorConflict(c, "Conflict")
 //	lib.ErrorConflict(c, errors.New("Conflict"))
 //	lib.ErrorConflict(c) // default response message is Conflict
+//	lib.ErrorConflict(c, db.Create(&data).Error) // database errors are translated by lib.DBErrors
 func ErrorConflict(c *fiber.Ctx, message ...interface{}) error {
 	if len(message) == 0 {
 		message = append(message, "Conflict")
@@ -203,79 +217,12 @@ func ErrorConflict(c *fiber.Ctx, message ...interface{}) error {
 		responseMessage = m.Error()
 	}
 
-	indexPrefixes := regexp.MustCompile(`^(unique|index|idx)_+`)
-
-	for p := range patterns {
-		pattern := patterns[p]
-		re := regexp.MustCompile(pattern.pattern)
-		if re.Match([]byte(responseMessage)) {
-			fieldPath := re.ReplaceAllString(responseMessage, "$1")
-			fieldPath = indexPrefixes.ReplaceAllString(fieldPath, "")
-			fieldPath = strings.ReplaceAll(fieldPath, "__", ".")
-			field := fieldPath
-			fields := strings.Split(field, ".")
-			if len(fields) > 1 {
-				names := []string{}
-				for _, i := range fields[1:] {
-					names = append(names, strcase.ToSnake(i))
-				}
-				field = strings.Join(names, ".")
-			}
-
-			// detect field name based on gorm default index
-			// set default gorm unique index example:
-			// `gorm:"type:varchar(36);index:,unique,where:deleted_at is null;not null"`
-			if !strings.Contains(fieldPath, ".") {
-				splits := strings.Split(fieldPath, "_")
-				splitLen := len(splits)
-				if splitLen > 2 {
-					splitIndex := (splitLen - 1) / 2
-					prefix := strings.Join(splits[0:splitIndex], "_")
-					suffixLen := len(prefix) + 1
-					if len(fieldPath) > suffixLen {
-						suffix := fieldPath[suffixLen:]
-						if strings.HasPrefix(suffix, prefix) {
-							field = suffix
-							fieldPath = prefix + "." + suffix
-						}
-					}
-				}
-			}
-			// nss := strings.Split(fieldPath, ".")
-			// nssString := []string{}
-			// for i := 0; i < len(nss); i++ {
-			// 	nssString = append(nssString, strcase.ToCamel(nss[i]))
-			// }
-			errorData := ErrorData{
-				// Namespace: strings.Join(nssString, "."),
-				Name:      strcase.ToSnake(field),
-				Path:      fieldPath,
-				Validator: pattern.validator,
-				Value:     nil,
-			}
-
-			errorData.Message = fmt.Sprintf("Invalid data %v", errorData.Name) //default message if validation translation not exists
-			if m, ok := validateMessages[pattern.validator]; ok {
-				errorData.Message = fmt.Sprintf(m, errorData.Name, "")
-			}
-
-			response := Response{
-				Message:          `Conflict`,
-				ErrorDescription: Strptr(`Duplicate value`),
-				ErrorData:        &[]ErrorData{errorData},
-			}
-
-			httpCode := 409
-
-			// fallback to bad request if not unique validator
-			if pattern.validator != "unique" {
-				httpCode = 400
-				response.Message = "Bad Request"
-				response.ErrorDescription = Strptr(responseMessage)
-			}
-
-			return Send(c, httpCode, response)
-		}
+	err, ok := message[0].(error)
+	if !ok {
+		err = errors.New(responseMessage)
+	}
+	if dbErr, ok := DBErrors.Translate(err); ok {
+		return sendDBError(c, dbErr)
 	}
 
 	return Send(c, 409, responseMessage)
@@ -311,84 +258,33 @@ func OK(c *fiber.Ctx, result ...interface{}) error {
 	return c.Status(200).JSON(result[0])
 }
 
-// Custom Response Loyalty Account
+// ErrorConflictLoyaltyAccount send http 409 conflict of loyalty account
+//
+// Deprecated: use ErrorConflict, customize the message with lib.DBErrors.Override
 func ErrorConflictLoyaltyAccount(c *fiber.Ctx, message ...interface{}) error {
-	if len(message) == 0 {
-		message = append(message, "Conflict")
-	}
+	return ErrorConflict(c, message...)
+}
 
-	responseMessage := "Conflict"
-	if m, ok := message[0].(string); ok {
-		responseMessage = m
-	} else if m, ok := message[0].(error); ok {
-		responseMessage = m.Error()
+// sendDBError send translated database error, the original error is described on bad request
+func sendDBError(c *fiber.Ctx, dbErr *DBError) error {
+	response := Response{
+		Message:          http.StatusText(dbErr.Status),
+		ErrorDescription: Strptr(dbErr.Error()),
+	}
+	switch dbErr.ErrorData.Validator {
+	case DBErrorUnique:
+		response.ErrorDescription = Strptr(`Duplicate value`)
+	case DBErrorForeignKey:
+		response.ErrorDescription = Strptr(`Referenced data`)
+	case DBErrorDeadlock:
+		response.ErrorDescription = Strptr(DBErrors.Message(GetLanguage(c), dbErr))
 	}
 
-	indexPrefixes := regexp.MustCompile(`^(unique|index|idx)_+`)
-
-	for p := range patterns {
-		pattern := patterns[p]
-		re := regexp.MustCompile(pattern.pattern)
-		if re.Match([]byte(responseMessage)) {
-			fieldPath := re.ReplaceAllString(responseMessage, "$1")
-			fieldPath = indexPrefixes.ReplaceAllString(fieldPath, "")
-			fieldPath = strings.ReplaceAll(fieldPath, "__", ".")
-			field := fieldPath
-			fields := strings.Split(field, ".")
-			if len(fields) > 1 {
-				names := []string{}
-				for _, i := range fields[1:] {
-					names = append(names, strcase.ToSnake(i))
-				}
-				field = strings.Join(names, ".")
-			}
-
-			if !strings.Contains(fieldPath, ".") {
-				splits := strings.Split(fieldPath, "_")
-				splitLen := len(splits)
-				if splitLen > 2 {
-					splitIndex := (splitLen - 1) / 2
-					prefix := strings.Join(splits[0:splitIndex], "_")
-					suffixLen := len(prefix) + 1
-					if len(fieldPath) > suffixLen {
-						suffix := fieldPath[suffixLen:]
-						if strings.HasPrefix(suffix, prefix) {
-							field = suffix
-							fieldPath = prefix + "." + suffix
-						}
-					}
-				}
-			}
-			errorData := ErrorData{
-				Name:      strcase.ToSnake(field),
-				Path:      fieldPath,
-				Validator: pattern.validator,
-				Value:     nil,
-				Message:   `Loyalty Account already exists`,
-			}
-
-			if m, ok := validateMessages[pattern.validator]; ok {
-				errorData.Message = fmt.Sprintf(m, errorData.Name, "")
-			}
-
-			response := Response{
-				Message:          `Conflict`,
-				ErrorDescription: Strptr(`Duplicate value`),
-				ErrorData:        &[]ErrorData{errorData},
-			}
-
-			httpCode := 409
-
-			// fallback to bad request if not unique validator
-			if pattern.validator != "unique" {
-				httpCode = 400
-				response.Message = "Bad Request"
-				response.ErrorDescription = Strptr(responseMessage)
-			}
-
-			return Send(c, httpCode, response)
-		}
+	errorData := dbErr.ErrorData
+	errorData.Message = DBErrors.Message(GetLanguage(c), dbErr)
+	if errorData.Name != "" || errorData.Path != "" {
+		response.ErrorData = &[]ErrorData{errorData}
 	}
 
-	return Send(c, 409, responseMessage)
+	return Send(c, dbErr.Status, response)
 }
@@ -2,22 +2,27 @@ package lib
 
 import (
 	"bytes"
-	"crypto/tls"
-	"fmt"
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
 
 type RestClient struct {
 	URL     string
 	Method  string
-	Timeout int
+	Timeout int // timeout of each attempt in seconds
 	Headers map[string]string
 	Request interface{}
+	Retry   RetryPolicy // retry policy of ExecuteContext, no retry by default
+
+	Client    HTTPClient        // http client, example: MockHTTPClient, Transport is not used when it is set
+	Transport http.RoundTripper // transport of the http client, default RestTransport
 }
 
 func (r *RestClient) SetURL(url string) *RestClient {
@@ -62,15 +67,61 @@ func (r *RestClient) SetRequest(param interface{}) *RestClient {
 	return r
 }
 
+// SetClient set http client, example: &lib.MockHTTPClient{}
+func (r *RestClient) SetClient(client HTTPClient) *RestClient {
+	r.Client = client
+	return r
+}
+
+// SetTransport set transport of the http client, see NewTransport
+func (r *RestClient) SetTransport(transport http.RoundTripper) *RestClient {
+	r.Transport = transport
+	return r
+}
+
+// SetRetry set retry policy of ExecuteContext
+func (r *RestClient) SetRetry(policy RetryPolicy) *RestClient {
+	r.Retry = policy
+	return r
+}
+
+// Execute send request, network failures are returned as status 0 with "Call URL Failed" body,
+// use ExecuteContext for cancellation, retries and errors
 func (r *RestClient) Execute() (httpBody string, httpStatus int) {
-	var restrequest []byte
-	restRequestID, _ := uuid.NewRandom()
+	res, err := r.ExecuteContext(context.Background())
+	if nil != err {
+		return "Call URL Failed : " + errors.Unwrap(err).Error(), 0
+	}
 
-	dataType := fmt.Sprintf("%T", r.Request)
+	return string(res.Body), res.Status
+}
 
-	if strings.Contains(dataType, "string") {
-		restrequest = []byte(r.Request.(string))
-	} else {
+// ExecuteContext send request with context cancellation and deadline, failed attempts are retried by the Retry policy,
+// err is a *RestError when no response is received, statuses of received responses are checked by RestResponse.Err
+//
+//	=> Example
+//	res, err := new(lib.RestClient).
+//		SetURL(url).
+//		SetMethod("GET").
+//		SetRetry(lib.RetryPolicy{MaxRetries: 3}).
+//		ExecuteContext(c.UserContext())
+//	if nil != err {
+//		return err // *lib.RestError, errors.Is(err, context.DeadlineExceeded) on timeout
+//	}
+//	if err := res.Err(); nil != err {
+//		return err // *lib.RestError with Status and Body
+//	}
+//	return res.JSON(&result)
+func (r *RestClient) ExecuteContext(ctx context.Context) (*RestResponse, error) {
+	restRequestID, _ := uuid.NewRandom()
+
+	var restrequest []byte
+	switch request := r.Request.(type) {
+	case string:
+		restrequest = []byte(request)
+	case []byte:
+		restrequest = request
+	default:
 		restrequest, _ = JSONMarshal(r.Request)
 	}
 
@@ -81,59 +132,108 @@ func (r *RestClient) Execute() (httpBody string, httpStatus int) {
 		r.Timeout = 15
 	}
 
-	// create request structure
-	req, _ := http.NewRequest(r.Method, r.URL, strings.NewReader(string(restrequest)))
+	client := r.httpClient()
+	policy := r.Retry.withDefaults()
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		// Now hit to destionation endpoint
+		LogStruct(map[string]interface{}{
+			"REQUESTID": restRequestID.String(),
+			"URL":       r.URL,
+			"METHOD":    r.Method,
+			"REQUEST":   string(restrequest),
+			"ATTEMPT":   attempt,
+		}, "RESTCLIENT REQUEST LOG")
+
+		res, err := r.attempt(ctx, client, restrequest, attempt)
+		if nil != res {
+			res.Duration = time.Since(start)
+		}
+		if nil != err {
+			log.Printf("Call URL Failed : %s", err.Error())
+		} else {
+			LogStruct(map[string]interface{}{
+				"REQUESTID": restRequestID.String(),
+				"STATUS":    res.Status,
+				"RESPONSE":  string(res.Body),
+			}, "REST CLIENT RESPONSE LOG")
+		}
+
+		if nil != ctx.Err() || attempt > policy.MaxRetries || !policy.RetryOn(res, err) {
+			if nil != err {
+				return nil, &RestError{Method: r.Method, URL: r.URL, Attempts: attempt, Err: err}
+			}
+			return res, nil
+		}
+
+		wait := policy.backoff(attempt)
+		if nil != res {
+			if retryAfter, ok := parseRetryAfter(res.Header.Get(fiber.HeaderRetryAfter)); ok {
+				wait = retryAfter
+				if wait > policy.MaxBackoff {
+					wait = policy.MaxBackoff
+				}
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if nil == err {
+				err = ctx.Err()
+			}
+			return nil, &RestError{Method: r.Method, URL: r.URL, Attempts: attempt, Err: err}
+		case <-timer.C:
+		}
+	}
+}
+
+// httpClient injected client, or the client of the transport
+func (r *RestClient) httpClient() HTTPClient {
+	if nil != r.Client {
+		return r.Client
+	}
+	if nil != r.Transport {
+		return &http.Client{Transport: r.Transport}
+	}
+	return &http.Client{Transport: RestTransport}
+}
+
+// attempt send request once, the timeout covers reading the response body
+func (r *RestClient) attempt(ctx context.Context, client HTTPClient, body []byte, attempt int) (*RestResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(r.Timeout)*time.Second)
+	defer cancel()
 
+	// create request structure, the body is recreated for every attempt
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(r.Method), r.URL, bytes.NewReader(body))
+	if nil != err {
+		return nil, err
+	}
 	for hname, hval := range r.Headers {
 		req.Header.Set(hname, hval)
 	}
-	req.Close = true // this is required to prevent too many files open
-
-	// Create HTTP Connection
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
-		Timeout: time.Duration(r.Timeout) * time.Second,
-	}
-
-	// Now hit to destionation endpoint
-	LogStruct(map[string]interface{}{
-		"REQUESTID": restRequestID.String(),
-		"URL":       r.URL,
-		"METHOD":    r.Method,
-		"REQUEST":   string(restrequest),
-	}, "RESTCLIENT REQUEST LOG")
+
 	res, err := client.Do(req)
-	if err != nil {
-		log.Printf("Call URL Failed : %s", err.Error())
-		httpStatus = 0
-		httpBody = "Call URL Failed : " + err.Error()
-		// if res != nil {
-		// 	buff := new(bytes.Buffer)
-		// 	buff.ReadFrom(res.Body)
-		// 	httpBody = buff.String()
-		// 	httpStatus = res.StatusCode
-		// 	log.Printf("Body : %s", httpBody)
-		// } else {
-		// 	httpBody = "Call URL Failed : " + err.Error()
-		// }
-		return
+	if nil != err {
+		return nil, err
 	}
 	defer res.Body.Close()
 
 	buff := new(bytes.Buffer)
-	buff.ReadFrom(res.Body)
-	httpBody = buff.String()
-	httpStatus = res.StatusCode
-
-	LogStruct(map[string]interface{}{
-		"REQUESTID": restRequestID.String(),
-		"RESPONSE":  httpBody,
-	}, "REST CLIENT RESPONSE LOG")
-	return
+	if _, err := buff.ReadFrom(res.Body); nil != err {
+		return nil, err
+	}
+
+	return &RestResponse{
+		Status:   res.StatusCode,
+		Header:   res.Header,
+		Body:     buff.Bytes(),
+		Attempts: attempt,
+		method:   r.Method,
+		url:      r.URL,
+	}, nil
 }
 
 // FindSlice Find string on slice
@@ -1,9 +1,13 @@
 package lib
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2/utils"
 )
@@ -73,6 +77,89 @@ func TestMethodsRestClient(t *testing.T) {
 	utils.AssertEqual(t, true, httpCode >= 200 || httpCode == 0, "Call Rest API with defined method")
 }
 
+func TestExecuteContextRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			utils.AssertEqual(t, "POST", r.Method)
+			w.Write([]byte(`{"id":1}`))
+		}
+	}))
+	defer server.Close()
+
+	res, err := new(RestClient).
+		SetURL(server.URL).
+		SetMethod("post").
+		SetRequest(map[string]int{"id": 1}).
+		SetRetry(RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}).
+		ExecuteContext(context.Background())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 200, res.Status)
+	utils.AssertEqual(t, 3, res.Attempts, "retried on 503 and 429")
+	utils.AssertEqual(t, true, res.IsSuccess())
+	utils.AssertEqual(t, nil, res.Err())
+	result := map[string]int{}
+	utils.AssertEqual(t, nil, res.JSON(&result))
+	utils.AssertEqual(t, 1, result["id"])
+}
+
+func TestExecuteContextNoRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("invalid"))
+	}))
+	defer server.Close()
+
+	client := RestClient{URL: server.URL, Retry: RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond}}
+	res, err := client.ExecuteContext(context.Background())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, int32(1), atomic.LoadInt32(&calls), "4xx is not retried")
+	utils.AssertEqual(t, "invalid", res.String())
+
+	var restErr *RestError
+	utils.AssertEqual(t, true, errors.As(res.Err(), &restErr))
+	utils.AssertEqual(t, 400, restErr.Status)
+	utils.AssertEqual(t, "GET", restErr.Method)
+	utils.AssertEqual(t, server.URL, restErr.URL)
+}
+
+func TestExecuteContextDeadline(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := RestClient{URL: server.URL, Retry: RetryPolicy{MaxRetries: 5, MinBackoff: time.Second}}
+	started := time.Now()
+	res, err := client.ExecuteContext(ctx)
+	utils.AssertEqual(t, true, nil == res)
+	utils.AssertEqual(t, true, errors.Is(err, context.DeadlineExceeded), "backoff is cancelled by the deadline")
+	utils.AssertEqual(t, true, time.Since(started) < time.Second)
+	utils.AssertEqual(t, int32(1), atomic.LoadInt32(&calls))
+
+	var restErr *RestError
+	utils.AssertEqual(t, true, errors.As(err, &restErr))
+	utils.AssertEqual(t, 1, restErr.Attempts)
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, err = client.ExecuteContext(cancelled)
+	utils.AssertEqual(t, true, errors.Is(err, context.Canceled), "cancelled before the request")
+}
+
 func TestFindSlice(t *testing.T) {
 	index, hasSlice := FindSlice([]string{"A", "B", "C"}, "C")
 	utils.AssertEqual(t, 2, index, "FindSlice index result")
@@ -0,0 +1,65 @@
+package lib
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RestResponse response of RestClient.ExecuteContext
+type RestResponse struct {
+	Status   int           // http status
+	Header   http.Header   // response headers
+	Body     []byte        // response body
+	Attempts int           // attempts sent, retries are Attempts - 1
+	Duration time.Duration // duration of every attempt and backoff
+
+	method string
+	url    string
+}
+
+// String response body
+func (r *RestResponse) String() string {
+	return string(r.Body)
+}
+
+// IsSuccess check 2xx status
+func (r *RestResponse) IsSuccess() bool {
+	return r.Status >= 200 && r.Status < 300
+}
+
+// JSON decode response body into v
+func (r *RestResponse) JSON(v interface{}) error {
+	return JSONUnmarshal(r.Body, v)
+}
+
+// Err *RestError of 4xx and 5xx status, nil otherwise
+func (r *RestResponse) Err() error {
+	if r.Status < 400 {
+		return nil
+	}
+	return &RestError{Method: r.method, URL: r.url, Status: r.Status, Body: r.Body, Attempts: r.Attempts}
+}
+
+// RestError error of RestClient.ExecuteContext,
+// Err is set when no response is received, otherwise Status and Body are the response
+type RestError struct {
+	Method   string
+	URL      string
+	Status   int
+	Body     []byte
+	Attempts int
+	Err      error
+}
+
+func (e *RestError) Error() string {
+	if nil != e.Err {
+		return fmt.Sprintf("%s %s failed after %d attempt(s): %s", e.Method, e.URL, e.Attempts, e.Err.Error())
+	}
+	return fmt.Sprintf("%s %s responded %d %s", e.Method, e.URL, e.Status, http.StatusText(e.Status))
+}
+
+// Unwrap cause of the error, example: context.DeadlineExceeded
+func (e *RestError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,96 @@
+package lib
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// default backoff of RetryPolicy
+const (
+	defaultRetryMinBackoff = 200 * time.Millisecond
+	defaultRetryMaxBackoff = 10 * time.Second
+)
+
+// RetryPolicy retry policy of RestClient.ExecuteContext, backoff is exponential with jitter,
+// Retry-After of the response replaces the backoff up to MaxBackoff,
+// every method is retried so it should only be enabled for idempotent requests
+//
+//	client.SetRetry(lib.RetryPolicy{MaxRetries: 3, MinBackoff: 100 * time.Millisecond})
+type RetryPolicy struct {
+	MaxRetries int                                     // retries after the first attempt, 0 disables retry
+	MinBackoff time.Duration                           // backoff of the first retry, default 200ms
+	MaxBackoff time.Duration                           // maximum backoff, default 10s
+	RetryOn    func(res *RestResponse, err error) bool // retry condition, default RetryOnDefault
+}
+
+// RetryOnDefault retry on 429, 5xx except 501 and connection reset or closed by the server
+func RetryOnDefault(res *RestResponse, err error) bool {
+	if nil != err {
+		return errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, io.EOF) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			strings.Contains(err.Error(), "connection reset by peer")
+	}
+	if nil == res {
+		return false
+	}
+	return res.Status == http.StatusTooManyRequests ||
+		(res.Status >= 500 && res.Status != http.StatusNotImplemented)
+}
+
+// withDefaults policy with default backoff and condition
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MinBackoff <= 0 {
+		p.MinBackoff = defaultRetryMinBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if p.MaxBackoff < p.MinBackoff {
+		p.MaxBackoff = p.MinBackoff
+	}
+	if nil == p.RetryOn {
+		p.RetryOn = RetryOnDefault
+	}
+	return p
+}
+
+// backoff wait before the retry of attempt, a random value between half and the full exponential backoff
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.MaxBackoff
+	if attempt < 32 {
+		if exponential := p.MinBackoff << uint(attempt-1); exponential > 0 && exponential < p.MaxBackoff {
+			backoff = exponential
+		}
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
+
+// parseRetryAfter Retry-After header of delay seconds or http date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); nil == err {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); nil == err {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
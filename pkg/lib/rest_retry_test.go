@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestRetryOnDefault(t *testing.T) {
+	utils.AssertEqual(t, true, RetryOnDefault(nil, fmt.Errorf("read: %w", syscall.ECONNRESET)), "connection reset")
+	utils.AssertEqual(t, true, RetryOnDefault(nil, io.EOF), "connection closed")
+	utils.AssertEqual(t, false, RetryOnDefault(nil, errors.New("no such host")))
+	utils.AssertEqual(t, true, RetryOnDefault(&RestResponse{Status: 429}, nil))
+	utils.AssertEqual(t, true, RetryOnDefault(&RestResponse{Status: 503}, nil))
+	utils.AssertEqual(t, false, RetryOnDefault(&RestResponse{Status: 501}, nil))
+	utils.AssertEqual(t, false, RetryOnDefault(&RestResponse{Status: 404}, nil))
+	utils.AssertEqual(t, false, RetryOnDefault(nil, nil))
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}.withDefaults()
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 5: time.Second, 100: time.Second} {
+		backoff := policy.backoff(attempt)
+		utils.AssertEqual(t, true, backoff >= max/2 && backoff <= max, fmt.Sprintf("attempt %d backoff %s", attempt, backoff))
+	}
+
+	defaults := RetryPolicy{}.withDefaults()
+	utils.AssertEqual(t, defaultRetryMinBackoff, defaults.MinBackoff)
+	utils.AssertEqual(t, defaultRetryMaxBackoff, defaults.MaxBackoff)
+	utils.AssertEqual(t, true, nil != defaults.RetryOn)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	wait, ok := parseRetryAfter("3")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, 3*time.Second, wait)
+
+	wait, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, true, wait > 59*time.Minute && wait <= time.Hour)
+
+	wait, ok = parseRetryAfter("Mon, 02 Jan 2006 15:04:05 GMT")
+	utils.AssertEqual(t, true, ok)
+	utils.AssertEqual(t, time.Duration(0), wait, "past date")
+
+	_, ok = parseRetryAfter("")
+	utils.AssertEqual(t, false, ok)
+	_, ok = parseRetryAfter("-1")
+	utils.AssertEqual(t, false, ok)
+	_, ok = parseRetryAfter("soon")
+	utils.AssertEqual(t, false, ok)
+}
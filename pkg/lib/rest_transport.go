@@ -0,0 +1,129 @@
+package lib
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// default pool of TransportConfig
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// RestTransport shared transport of RestClient, certificates are verified and connections are reused,
+// replace it with NewTransport to trust a private CA or send client certificates
+//
+//	=> Example
+//	transport, err := lib.NewTransport(lib.TransportConfig{
+//		CAFile:   viper.GetString("REST_CA_FILE"),
+//		CertFile: viper.GetString("REST_CERT_FILE"),
+//		KeyFile:  viper.GetString("REST_KEY_FILE"),
+//	})
+//	if nil != err {
+//		log.Fatal(err)
+//	}
+//	lib.RestTransport = transport
+var RestTransport http.RoundTripper = func() http.RoundTripper {
+	transport, _ := NewTransport(TransportConfig{})
+	return transport
+}()
+
+// TransportConfig options of NewTransport
+type TransportConfig struct {
+	CAFile              string            // PEM CA bundle trusted in addition to the system roots
+	RootCAs             *x509.CertPool    // trusted roots, replaces the system roots
+	CertFile            string            // PEM client certificate of mutual TLS
+	KeyFile             string            // PEM client key of mutual TLS
+	Certificates        []tls.Certificate // client certificates of mutual TLS
+	InsecureSkipVerify  bool              // disable certificate verification, for development only
+	MaxIdleConns        int               // idle connections across all hosts, default 100
+	MaxIdleConnsPerHost int               // idle connections of a host, default 10
+	MaxConnsPerHost     int               // connections of a host, default unlimited
+	IdleConnTimeout     time.Duration     // idle connection lifetime, default 90s
+	TLSConfig           *tls.Config       // base tls config, cloned before the options above are applied
+}
+
+// NewTransport create http transport with connection pool and TLS options
+func NewTransport(config TransportConfig) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if nil != err {
+		return nil, err
+	}
+
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = defaultMaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost <= 0 {
+		config.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout <= 0 {
+		config.IdleConnTimeout = defaultIdleConnTimeout
+	}
+
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+	}, nil
+}
+
+// newTLSConfig tls config of TransportConfig
+func newTLSConfig(config TransportConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if nil != config.TLSConfig {
+		tlsConfig = config.TLSConfig.Clone()
+	}
+
+	if nil != config.RootCAs {
+		tlsConfig.RootCAs = config.RootCAs
+	}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if nil != err {
+			return nil, err
+		}
+		if nil == tlsConfig.RootCAs {
+			if tlsConfig.RootCAs, err = x509.SystemCertPool(); nil != err {
+				tlsConfig.RootCAs = x509.NewCertPool()
+			}
+		} else {
+			// the caller pool may be shared, the bundle is added to a copy
+			tlsConfig.RootCAs = tlsConfig.RootCAs.Clone()
+		}
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificate found in " + config.CAFile)
+		}
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if nil != err {
+			return nil, err
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, certificate)
+	}
+	tlsConfig.Certificates = append(tlsConfig.Certificates, config.Certificates...)
+
+	if config.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,101 @@
+package lib
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestNewTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+
+	_, err := new(RestClient).SetURL(server.URL).ExecuteContext(context.Background())
+	utils.AssertEqual(t, true, nil != err, "certificates are verified by default")
+
+	insecure, err := NewTransport(TransportConfig{InsecureSkipVerify: true})
+	utils.AssertEqual(t, nil, err)
+	res, err := new(RestClient).SetURL(server.URL).SetTransport(insecure).ExecuteContext(context.Background())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "OK", res.String(), "insecure opt in")
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+	trusted, err := NewTransport(TransportConfig{CAFile: caFile, MaxIdleConnsPerHost: 2, IdleConnTimeout: time.Second})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 2, trusted.MaxIdleConnsPerHost)
+	utils.AssertEqual(t, time.Second, trusted.IdleConnTimeout)
+	utils.AssertEqual(t, defaultMaxIdleConns, trusted.MaxIdleConns)
+	res, err = new(RestClient).SetURL(server.URL).SetTransport(trusted).ExecuteContext(context.Background())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 200, res.Status, "custom CA bundle")
+
+	shared := x509.NewCertPool()
+	_, err = NewTransport(TransportConfig{CAFile: caFile, RootCAs: shared})
+	utils.AssertEqual(t, nil, err)
+	_, err = NewTransport(TransportConfig{CAFile: caFile, TLSConfig: &tls.Config{RootCAs: shared}})
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, true, shared.Equal(x509.NewCertPool()), "caller pool is not modified")
+
+	emptyFile := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(emptyFile, []byte("empty"), 0600)
+	_, err = NewTransport(TransportConfig{CAFile: emptyFile})
+	utils.AssertEqual(t, true, nil != err, "CA bundle without certificate")
+
+	_, err = NewTransport(TransportConfig{CertFile: "missing.pem", KeyFile: "missing.key"})
+	utils.AssertEqual(t, true, nil != err, "missing client certificate")
+}
+
+func TestTransportClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.Organization[0]))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	transport, err := NewTransport(TransportConfig{
+		RootCAs:      server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+		Certificates: server.TLS.Certificates,
+	})
+	utils.AssertEqual(t, nil, err)
+
+	res, err := new(RestClient).SetURL(server.URL).SetTransport(transport).ExecuteContext(context.Background())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, "Acme Co", res.String(), "client certificate is sent")
+}
+
+func TestRestClientMockHTTPClient(t *testing.T) {
+	app := fiber.New()
+	app.Post("/bookings", func(c *fiber.Ctx) error {
+		return c.Status(201).Send(c.Body())
+	})
+
+	mock := &MockHTTPClient{}
+	mock.SetApp(app)
+
+	res, err := new(RestClient).
+		SetURL("/bookings").
+		SetMethod("POST").
+		SetRequest(`{"id":1}`).
+		SetClient(mock).
+		ExecuteContext(context.Background())
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 201, res.Status)
+	utils.AssertEqual(t, `{"id":1}`, res.String())
+
+	_, status := new(RestClient).SetURL("/bookings").SetMethod("POST").SetClient(mock).Execute()
+	utils.AssertEqual(t, 201, status, "Execute with injected client")
+}
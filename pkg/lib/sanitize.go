@@ -0,0 +1,155 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// sanitizeTag struct tag of sanitizers, applied from left to right
+const sanitizeTag = "sanitize"
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+var sanitizers = struct {
+	sync.RWMutex
+	funcs map[string]func(string) string
+}{funcs: map[string]func(string) string{
+	"trim":            strings.TrimSpace,
+	"lower":           strings.ToLower,
+	"upper":           strings.ToUpper,
+	"upper_ascii":     upperASCII,
+	"collapse_spaces": collapseSpaces,
+	"strip_html":      stripHTML,
+	"email":           FormatEmail,
+}}
+
+// RegisterSanitizer add or replace a sanitizer of the sanitize tag
+//
+//	lib.RegisterSanitizer("digits", func(s string) string {
+//		return strings.Map(func(r rune) rune { ... }, s)
+//	})
+func RegisterSanitizer(name string, fn func(string) string) {
+	sanitizers.Lock()
+	defer sanitizers.Unlock()
+	sanitizers.funcs[name] = fn
+}
+
+// Sanitize normalise string fields of payload in place by their sanitize tag,
+// nested structs, pointers and slices are walked, it is applied by BodyParser, QueryParser and Bind
+//
+//	=> Example
+//	type CreateAgent struct {
+//		Name    string   `json:"name" sanitize:"trim,collapse_spaces,strip_html"`
+//		Email   *string  `json:"email" sanitize:"email"`
+//		Code    string   `json:"code" sanitize:"trim,upper_ascii"`
+//		Tags    []string `json:"tags" sanitize:"trim,lower"`
+//		Address Address  `json:"address"` // fields of Address are sanitized by their own tags
+//	}
+//
+//	sanitizers: trim, lower, upper, upper_ascii, collapse_spaces, strip_html, email, see RegisterSanitizer
+func Sanitize(payload interface{}) error {
+	return sanitizeValue(reflect.ValueOf(payload), nil)
+}
+
+// sanitizeValue apply funcs to strings of value, struct fields use their own tags
+func sanitizeValue(value reflect.Value, funcs []func(string) string) error {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return sanitizeValue(value.Elem(), funcs)
+	case reflect.String:
+		if len(funcs) == 0 || !value.CanSet() {
+			return nil
+		}
+		str := value.String()
+		for _, fn := range funcs {
+			str = fn(str)
+		}
+		value.SetString(str)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := sanitizeValue(value.Index(i), funcs); nil != err {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			fieldFuncs, err := sanitizeFuncs(field.Tag.Get(sanitizeTag))
+			if nil != err {
+				return fmt.Errorf("%s.%s: %s", value.Type().Name(), field.Name, err.Error())
+			}
+			if err := sanitizeValue(value.Field(i), fieldFuncs); nil != err {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sanitizeFuncs sanitizers of tag, unknown names are reported as error
+func sanitizeFuncs(tag string) ([]func(string) string, error) {
+	if tag == "" || tag == "-" {
+		return nil, nil
+	}
+
+	sanitizers.RLock()
+	defer sanitizers.RUnlock()
+
+	funcs := []func(string) string{}
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		fn, ok := sanitizers.funcs[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown sanitizer %q", name)
+		}
+		funcs = append(funcs, fn)
+	}
+	return funcs, nil
+}
+
+// upperASCII uppercase ASCII letters only, other characters are kept as is
+func upperASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}, s)
+}
+
+// collapseSpaces replace every whitespace run with a single space
+func collapseSpaces(s string) string {
+	builder := strings.Builder{}
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			if !space {
+				builder.WriteRune(' ')
+			}
+			space = true
+			continue
+		}
+		space = false
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// stripHTML remove html tags, entities are kept escaped
+func stripHTML(s string) string {
+	return htmlTagPattern.ReplaceAllString(s, "")
+}
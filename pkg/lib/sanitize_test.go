@@ -0,0 +1,99 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestSanitize(t *testing.T) {
+	type address struct {
+		City string `json:"city" sanitize:"trim,upper_ascii"`
+	}
+	type sample struct {
+		Name      string     `json:"name" sanitize:"trim,collapse_spaces,strip_html"`
+		Email     *string    `json:"email" sanitize:"email"`
+		Code      string     `json:"code" sanitize:"trim,upper_ascii"`
+		Tags      []string   `json:"tags" sanitize:"trim,lower"`
+		Note      string     `json:"note"`
+		Address   address    `json:"address"`
+		Addresses []*address `json:"addresses"`
+		Missing   *address   `json:"missing"`
+	}
+
+	data := sample{
+		Name:      "  <b>John</b> \t\n Doe ",
+		Email:     Strptr(" John.Doe @Example.com "),
+		Code:      " cgk-é ",
+		Tags:      []string{" VIP ", "Corporate"},
+		Note:      "  kept  ",
+		Address:   address{City: " jakarta "},
+		Addresses: []*address{{City: "bandung "}, nil},
+	}
+	utils.AssertEqual(t, nil, Sanitize(&data))
+	utils.AssertEqual(t, "John Doe", data.Name)
+	utils.AssertEqual(t, "john.doe@example.com", *data.Email)
+	utils.AssertEqual(t, "CGK-é", data.Code, "non ascii letters are kept")
+	utils.AssertEqual(t, []string{"vip", "corporate"}, data.Tags)
+	utils.AssertEqual(t, "  kept  ", data.Note, "fields without tag")
+	utils.AssertEqual(t, "JAKARTA", data.Address.City, "nested struct")
+	utils.AssertEqual(t, "BANDUNG", data.Addresses[0].City, "slice of pointers")
+
+	type unknown struct {
+		Name string `sanitize:"trim,unknown"`
+	}
+	err := Sanitize(&unknown{})
+	utils.AssertEqual(t, true, nil != err && strings.Contains(err.Error(), `"unknown"`), "unknown sanitizer")
+
+	RegisterSanitizer("reverse", func(s string) string {
+		runes := []rune(s)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes)
+	})
+	type custom struct {
+		Name string `sanitize:"reverse"`
+	}
+	reversed := custom{Name: "abc"}
+	utils.AssertEqual(t, nil, Sanitize(&reversed))
+	utils.AssertEqual(t, "cba", reversed.Name, "registered sanitizer")
+}
+
+func TestBodyParserSanitize(t *testing.T) {
+	type sample struct {
+		Name string `json:"name" query:"name" sanitize:"trim,collapse_spaces" validate:"required,max=8"`
+	}
+
+	app := fiber.New()
+	app.Post("/body", func(c *fiber.Ctx) error {
+		data := new(sample)
+		if err := BodyParser(c, data); nil != err {
+			return ErrorBadRequest(c, err)
+		}
+		return SendData(c, data.Name)
+	})
+	app.Get("/query", func(c *fiber.Ctx) error {
+		data := new(sample)
+		if err := QueryParser(c, data); nil != err {
+			return ErrorBadRequest(c, err)
+		}
+		return SendData(c, data.Name)
+	})
+
+	res, body, err := PostTest(app, "/body", nil, `{"name":"  john    doe  "}`)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 200, res.StatusCode, "sanitized before validation")
+	utils.AssertEqual(t, "john doe", body["data"])
+
+	res, _, err = PostTest(app, "/body", nil, `{"name":"   "}`)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 400, res.StatusCode, "blank name")
+
+	res, body, err = GetTest(app, "/query?name=%20john%20%20doe%20", nil)
+	utils.AssertEqual(t, nil, err)
+	utils.AssertEqual(t, 200, res.StatusCode)
+	utils.AssertEqual(t, "john doe", body["data"])
+}
@@ -0,0 +1,173 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const sortParamName = "sort"
+
+// null ordering of SortItem
+const (
+	SortNullsFirst = "FIRST"
+	SortNullsLast  = "LAST"
+)
+
+// sortFieldPattern API sort field name, dash is allowed inside the name
+var sortFieldPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*(\.[A-Za-z_][A-Za-z0-9_-]*)*$`)
+
+// SortItem single validated sort field
+type SortItem struct {
+	Field  string // API field name
+	Column string // column expression
+	Desc   bool
+	Nulls  string // SortNullsFirst, SortNullsLast or empty for database default
+}
+
+// SortItems validated sort fields
+type SortItems []SortItem
+
+// ParseSort parse and validate sort parameter, a leading dash sorts descending,
+// :nulls_first or :nulls_last suffix controls null ordering and table__column sorts by joined relation column like filters
+//
+//	=> Example
+//	items, err := lib.ParseSort("-created_at:nulls_last,agent__name")
+//	db.Order(items.OrderBy(lib.DialectOf(db))) // postgres: "created_at" DESC NULLS LAST,"Agent__name" ASC
+func ParseSort(sort string) (SortItems, error) {
+	return parseSort(sort, nil)
+}
+
+func parseSort(sort string, schema *QuerySchema) (SortItems, error) {
+	items := SortItems{}
+	errs := FilterErrors{}
+	fail := func(i int, value interface{}, format string, args ...interface{}) {
+		errs = append(errs, FilterError{Name: sortParamName, Position: []int{i}, Value: value, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i, val := range strings.Split(sort, ",") {
+		val = strings.TrimSpace(val)
+		if val == "" {
+			continue
+		}
+
+		item := SortItem{Field: val}
+		if strings.HasPrefix(item.Field, "-") {
+			item.Field = strings.TrimPrefix(item.Field, "-")
+			item.Desc = true
+		}
+		if field, modifier, found := strings.Cut(item.Field, ":"); found {
+			item.Field = field
+			switch strings.ToLower(strings.ReplaceAll(modifier, "_", "")) {
+			case "nullsfirst":
+				item.Nulls = SortNullsFirst
+			case "nullslast":
+				item.Nulls = SortNullsLast
+			default:
+				fail(i, val, "unsupported sort modifier %s", modifier)
+				continue
+			}
+		}
+		if !sortFieldPattern.MatchString(item.Field) {
+			fail(i, val, "invalid sort field %s", item.Field)
+			continue
+		}
+
+		if nil != schema {
+			field, ok := schema.Fields[item.Field]
+			if !ok || !field.Sortable {
+				fail(i, item.Field, "field %s is not sortable", item.Field)
+				continue
+			}
+			item.Column = field.Column
+		}
+		items = append(items, item)
+	}
+
+	if len(errs) > 0 {
+		return items, errs
+	}
+
+	return items, nil
+}
+
+// OrderBy compile sort items into ORDER BY clause of the dialect
+//
+//gocyclo:ignore
+func (s SortItems) OrderBy(dialect FilterDialect) string {
+	orders := []string{}
+	for _, item := range s {
+		column := item.Column
+		if column == "" {
+			column = sortColumn(item.Field, dialect)
+		}
+
+		direction := "ASC"
+		if item.Desc {
+			direction = "DESC"
+		}
+
+		switch {
+		case item.Nulls == "":
+			orders = append(orders, column+" "+direction)
+		case dialect == DialectMySQL:
+			// mysql sorts nulls first on ascending order and has no NULLS FIRST / LAST
+			nulls := "ASC"
+			if item.Nulls == SortNullsFirst {
+				nulls = "DESC"
+			}
+			orders = append(orders, column+" IS NULL "+nulls, column+" "+direction)
+		default:
+			orders = append(orders, column+" "+direction+" NULLS "+item.Nulls)
+		}
+	}
+
+	return strings.Join(orders, ",")
+}
+
+// sortColumn column expression of API sort field, table__column is mapped into the joined relation column
+// by NormalizeFieldName like the filter fields
+//
+//	agent__name -> "Agent__name"
+func sortColumn(field string, dialect FilterDialect) string {
+	segments := []string{}
+	for _, path := range strings.Split(field, ".") {
+		name := NormalizeFieldName(path)
+		if dialect == "" && !strings.Contains(name, "-") && !strings.Contains(name, "__") {
+			segments = append(segments, name)
+			continue
+		}
+		segments = append(segments, quoteIdentifier(name, dialect))
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// quoteIdentifier quote a single identifier, double quote is used when dialect is unknown
+func quoteIdentifier(identifier string, dialect FilterDialect) string {
+	quote := `"`
+	if dialect == DialectMySQL {
+		quote = "`"
+	}
+	return quote + strings.ReplaceAll(identifier, quote, quote+quote) + quote
+}
+
+// ParseSort parse and validate sort parameter against sortable fields of the schema
+func (s QuerySchema) ParseSort(sort string) (SortItems, error) {
+	return parseSort(sort, &s)
+}
+
+// OrderBy validated ORDER BY clause of sort parameter
+//
+//	=> Example
+//	orderBy, err := schema.WithDB(db).OrderBy(c.Query("sort"))
+//	if nil != err {
+//		return lib.ErrorBadRequest(c, err)
+//	}
+func (s QuerySchema) OrderBy(sort string) (string, error) {
+	items, err := s.ParseSort(sort)
+	if nil != err {
+		return "", err
+	}
+	return items.OrderBy(s.Dialect), nil
+}
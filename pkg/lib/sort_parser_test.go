@@ -0,0 +1,62 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+func TestParseSort(t *testing.T) {
+	items, err := ParseSort("-created_at:nulls_last, agent__name,check-in:NULLSFIRST")
+	utils.AssertEqual(t, nil, err, "parse sort")
+	utils.AssertEqual(t, SortItems{
+		{Field: "created_at", Desc: true, Nulls: SortNullsLast},
+		{Field: "agent__name"},
+		{Field: "check-in", Nulls: SortNullsFirst},
+	}, items, "sort items")
+
+	utils.AssertEqual(t, `created_at DESC NULLS LAST,"Agent__name" ASC,"check-in" ASC NULLS FIRST`, items.OrderBy(""), "default order by")
+	utils.AssertEqual(t, `"created_at" DESC NULLS LAST,"Agent__name" ASC,"check-in" ASC NULLS FIRST`, items.OrderBy(DialectPostgres), "postgres order by")
+	utils.AssertEqual(t, "`created_at` IS NULL ASC,`created_at` DESC,`Agent__name` ASC,`check-in` IS NULL DESC,`check-in` ASC", items.OrderBy(DialectMySQL), "mysql order by")
+
+	items, err = ParseSort("name;DROP TABLE users,-id:random,(select 1)")
+	errs, ok := err.(FilterErrors)
+	utils.AssertEqual(t, true, ok, "typed errors")
+	utils.AssertEqual(t, 3, len(errs), "invalid fields")
+	utils.AssertEqual(t, "sort[1]", errs[1].Path(), "error path")
+	utils.AssertEqual(t, 0, len(items), "no valid items")
+}
+
+func TestQuerySchemaOrderBy(t *testing.T) {
+	schema := QuerySchema{Dialect: DialectPostgres, Fields: map[string]QueryField{
+		"name":   {Sortable: true},
+		"total":  {Column: "SUM(amount)", Sortable: true},
+		"status": {Filterable: true},
+	}}
+
+	orderBy, err := schema.OrderBy("-total:nulls_last,name")
+	utils.AssertEqual(t, nil, err, "order by")
+	utils.AssertEqual(t, `SUM(amount) DESC NULLS LAST,"name" ASC`, orderBy, "order by")
+
+	_, err = schema.OrderBy("status,secret")
+	errs, _ := err.(FilterErrors)
+	utils.AssertEqual(t, 2, len(errs), "not sortable")
+	utils.AssertEqual(t, "field secret is not sortable", errs[1].Message, "unknown field")
+}
+
+func TestSortRelationColumn(t *testing.T) {
+	// relation fields are sorted by the same column as they are filtered
+	schema := QuerySchema{Dialect: DialectPostgres, Fields: map[string]QueryField{
+		"agent__name": {Type: QueryFieldString, Filterable: true, Sortable: true},
+	}}
+	filters, _, _, _, err := schema.CustomFilters(`["agent__name","=","john"]`, "", "")
+	utils.AssertEqual(t, nil, err, "filter")
+	utils.AssertEqual(t, `"Agent__name" = ?`, filters, "filter column")
+
+	orderBy, err := schema.OrderBy("agent__name")
+	utils.AssertEqual(t, nil, err, "schema order by")
+	utils.AssertEqual(t, `"Agent__name" ASC`, orderBy, "schema sort column")
+
+	items, _ := ParseSort("agent__name")
+	utils.AssertEqual(t, orderBy, items.OrderBy(DialectPostgres), "sort column without schema")
+}
@@ -0,0 +1,321 @@
+// Package travel validation tags of travel data, they are registered on lib.VALIDATOR when the package is imported.
+//
+// Currencies and countries are validated against the embedded ISO 4217 and ISO 3166-1 lists.
+// IATA does not publish a free airport and airline list, so iata_airport and iata_airline are format checks
+// (3 letters, 2 character designator) until the service loads its reference data
+// with SetAirports / LoadAirports and SetAirlines / LoadAirlines, then only the loaded codes are valid.
+//
+//	=> Example
+//	f, err := os.Open(viper.GetString("AIRPORTS_FILE")) // one code per line
+//	...
+//	if err := travel.LoadAirports(f); nil != err {
+//		log.Fatal(err)
+//	}
+package travel
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/terra-discover/bbcrs-helper-lib/pkg/lib"
+)
+
+//go:embed data/*.txt
+var data embed.FS
+
+// validation tags
+const (
+	TagAirport        = "iata_airport"    // IATA airport code format, example: CGK, codes loaded by SetAirports or LoadAirports are enforced
+	TagAirline        = "iata_airline"    // IATA airline designator format, example: GA, codes loaded by SetAirlines or LoadAirlines are enforced
+	TagCurrency       = "iso_currency"    // ISO 4217 currency code, example: IDR
+	TagCountry        = "iso_country"     // ISO 3166-1 alpha-2 country code, example: ID
+	TagPNR            = "pnr"             // PNR record locator, example: X7K9QD
+	TagPassportNumber = "passport_number" // passport number, example: A1234567
+	TagPassportExpiry = "passport_expiry" // passport expiry date, parameter is the minimum validity in months, default 6
+	TagCardNumber     = "card_number"     // payment card number with Luhn check, spaces and dashes are allowed
+)
+
+// DefaultPassportValidityMonths minimum passport validity of passport_expiry without parameter
+const DefaultPassportValidityMonths = 6
+
+var (
+	airportPattern        = regexp.MustCompile(`^[A-Z]{3}$`)
+	airlinePattern        = regexp.MustCompile(`^([A-Z][A-Z0-9]|[0-9][A-Z])$`)
+	pnrPattern            = regexp.MustCompile(`^[A-Z0-9]{6}$`)
+	passportNumberPattern = regexp.MustCompile(`^[A-Z0-9]{6,9}$`)
+	cardNumberPattern     = regexp.MustCompile(`^[0-9]{12,19}$`)
+	cardNumberSeparator   = strings.NewReplacer(" ", "", "-", "")
+)
+
+// complete ISO reference data loaded from the embedded files
+var (
+	currencies = mustLoadCodes("currencies.txt")
+	countries  = mustLoadCodes("countries.txt")
+)
+
+// airport and airline reference data loaded by the service, only the format is validated while they are empty
+var (
+	airports = &codeSet{codes: map[string]bool{}}
+	airlines = &codeSet{codes: map[string]bool{}}
+)
+
+// now current time of passport_expiry, replaced by tests
+var now = time.Now
+
+func init() {
+	if err := Register(lib.VALIDATOR); nil != err {
+		panic(err)
+	}
+}
+
+// Register register travel validations on validator, they are registered on lib.VALIDATOR when the package is imported
+//
+//	=> Example
+//	import _ "github.com/terra-discover/bbcrs-helper-lib/pkg/lib/travel"
+//
+//	type Flight struct {
+//		Origin      string    `json:"origin" validate:"required,iata_airport"`
+//		Carrier     string    `json:"carrier" validate:"required,iata_airline"`
+//		Currency    string    `json:"currency" validate:"required,iso_currency"`
+//		Nationality string    `json:"nationality" validate:"required,iso_country"`
+//		BookingCode string    `json:"booking_code" validate:"omitempty,pnr"`
+//		Passport    string    `json:"passport" validate:"required,passport_number"`
+//		ExpiryDate  time.Time `json:"expiry_date" validate:"required,passport_expiry=6"`
+//		CardNumber  string    `json:"card_number" validate:"omitempty,card_number"`
+//	}
+func Register(v *validator.Validate) error {
+	validations := map[string]validator.Func{
+		TagAirport:        stringValidator(IsAirport),
+		TagAirline:        stringValidator(IsAirline),
+		TagCurrency:       stringValidator(IsCurrency),
+		TagCountry:        stringValidator(IsCountry),
+		TagPNR:            stringValidator(pnrPattern.MatchString),
+		TagPassportNumber: stringValidator(passportNumberPattern.MatchString),
+		TagPassportExpiry: validatePassportExpiry,
+		TagCardNumber:     stringValidator(IsCardNumber),
+	}
+	for tag, fn := range validations {
+		if err := v.RegisterValidation(tag, fn); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetAirports replace the airport codes accepted by iata_airport, no codes validates the format only,
+// IATA does not publish a free list so services load their own reference data
+//
+//	=> Example
+//	codes, err := repository.ActiveAirportCodes(ctx)
+//	...
+//	travel.SetAirports(codes...)
+func SetAirports(codes ...string) {
+	airports.set(codes...)
+}
+
+// AddAirports add airport codes accepted by iata_airport
+func AddAirports(codes ...string) {
+	airports.add(codes...)
+}
+
+// LoadAirports replace the airport codes by a reader of one code per line, lines starting with # are comments
+func LoadAirports(r io.Reader) error {
+	codes, err := readCodes(r)
+	if nil != err {
+		return err
+	}
+	airports.set(codes...)
+	return nil
+}
+
+// SetAirlines replace the airline designators accepted by iata_airline, no codes validates the format only
+func SetAirlines(codes ...string) {
+	airlines.set(codes...)
+}
+
+// AddAirlines add airline designators accepted by iata_airline
+func AddAirlines(codes ...string) {
+	airlines.add(codes...)
+}
+
+// LoadAirlines replace the airline designators by a reader of one code per line, lines starting with # are comments
+func LoadAirlines(r io.Reader) error {
+	codes, err := readCodes(r)
+	if nil != err {
+		return err
+	}
+	airlines.set(codes...)
+	return nil
+}
+
+// IsAirport check IATA airport code format, and the loaded codes when they are set, case-insensitive,
+// ZZZ is valid until reference data is loaded
+func IsAirport(code string) bool {
+	code = normalizeCode(code)
+	return airportPattern.MatchString(code) && (airports.empty() || airports.has(code))
+}
+
+// IsAirline check IATA airline designator format, and the loaded codes when they are set, case-insensitive
+func IsAirline(code string) bool {
+	code = normalizeCode(code)
+	return airlinePattern.MatchString(code) && (airlines.empty() || airlines.has(code))
+}
+
+// IsCurrency check ISO 4217 currency code, case-insensitive
+func IsCurrency(code string) bool {
+	return currencies.has(code)
+}
+
+// IsCountry check ISO 3166-1 alpha-2 country code, case-insensitive
+func IsCountry(code string) bool {
+	return countries.has(code)
+}
+
+// IsCardNumber check payment card number length and Luhn checksum, spaces and dashes are ignored
+//
+//	travel.IsCardNumber("4111 1111 1111 1111") // true
+func IsCardNumber(number string) bool {
+	number = cardNumberSeparator.Replace(number)
+	if !cardNumberPattern.MatchString(number) {
+		return false
+	}
+
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if (len(number)-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// codeSet set of uppercase reference codes
+type codeSet struct {
+	mu    sync.RWMutex
+	codes map[string]bool
+}
+
+func (s *codeSet) add(codes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, code := range codes {
+		s.codes[normalizeCode(code)] = true
+	}
+}
+
+func (s *codeSet) set(codes ...string) {
+	replaced := map[string]bool{}
+	for _, code := range codes {
+		replaced[normalizeCode(code)] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes = replaced
+}
+
+func (s *codeSet) has(code string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.codes[normalizeCode(code)]
+}
+
+func (s *codeSet) empty() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.codes) == 0
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// mustLoadCodes load embedded data file
+func mustLoadCodes(file string) *codeSet {
+	f, err := data.Open(path.Join("data", file))
+	if nil != err {
+		panic(err)
+	}
+	defer f.Close()
+
+	codes, err := readCodes(f)
+	if nil != err {
+		panic(fmt.Sprintf("travel: %s, %s", file, err.Error()))
+	}
+	set := &codeSet{codes: map[string]bool{}}
+	set.add(codes...)
+	return set
+}
+
+// readCodes read one code per line, lines starting with # are comments
+func readCodes(r io.Reader) ([]string, error) {
+	codes := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		codes = append(codes, line)
+	}
+	return codes, scanner.Err()
+}
+
+func stringValidator(valid func(string) bool) validator.Func {
+	return func(f validator.FieldLevel) bool {
+		return f.Field().Kind() == reflect.String && valid(f.Field().String())
+	}
+}
+
+// validatePassportExpiry passport must be valid for the parameter months from today,
+// supports time.Time, strfmt.Date, strfmt.DateTime and YYYY-MM-DD string
+func validatePassportExpiry(f validator.FieldLevel) bool {
+	months := DefaultPassportValidityMonths
+	if param := f.Param(); param != "" {
+		value, err := strconv.Atoi(param)
+		if nil != err {
+			panic(fmt.Sprintf("travel: invalid %s parameter %q", TagPassportExpiry, param))
+		}
+		months = value
+	}
+
+	var expiry time.Time
+	switch value := f.Field().Interface().(type) {
+	case time.Time:
+		expiry = value
+	case strfmt.Date:
+		expiry = time.Time(value)
+	case strfmt.DateTime:
+		expiry = time.Time(value)
+	case string:
+		date, err := time.Parse("2006-01-02", value)
+		if nil != err {
+			return false
+		}
+		expiry = date
+	default:
+		return false
+	}
+	if expiry.IsZero() {
+		return false
+	}
+
+	year, month, day := now().Date()
+	minimum := time.Date(year, month, day, 0, 0, 0, 0, expiry.Location()).AddDate(0, months, 0)
+	return !expiry.Before(minimum)
+}
@@ -0,0 +1,130 @@
+package travel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/terra-discover/bbcrs-helper-lib/pkg/lib"
+)
+
+func TestCodes(t *testing.T) {
+	defer SetAirports()
+	defer SetAirlines()
+
+	utils.AssertEqual(t, true, IsAirport("CGK"))
+	utils.AssertEqual(t, true, IsAirport("cgk"), "lowercase")
+	utils.AssertEqual(t, true, IsAirport("MSP"), "format only without configured airports")
+	utils.AssertEqual(t, true, IsAirport("ZZZ"), "documented format check without reference data")
+	utils.AssertEqual(t, false, IsAirport("CG1"))
+	utils.AssertEqual(t, false, IsAirport("CGKK"))
+	utils.AssertEqual(t, true, IsAirline("GA"))
+	utils.AssertEqual(t, true, IsAirline("8B"))
+	utils.AssertEqual(t, true, IsAirline("xt"), "lowercase")
+	utils.AssertEqual(t, false, IsAirline("G"))
+	utils.AssertEqual(t, false, IsAirline("88"))
+	utils.AssertEqual(t, true, IsCurrency("IDR"))
+	utils.AssertEqual(t, true, IsCurrency("idr"), "lowercase")
+	utils.AssertEqual(t, false, IsCurrency("IDX"))
+	utils.AssertEqual(t, true, IsCountry("ID"))
+	utils.AssertEqual(t, true, IsCountry("id"), "lowercase")
+	utils.AssertEqual(t, false, IsCountry("XX"))
+
+	SetAirports("cgk", "DPS")
+	utils.AssertEqual(t, true, IsAirport("CGK"), "configured airport")
+	utils.AssertEqual(t, false, IsAirport("ZZZ"), "reference data is enforced once loaded")
+	utils.AssertEqual(t, false, IsAirport("MSP"), "airport outside configured codes")
+	AddAirports("msp")
+	utils.AssertEqual(t, true, IsAirport("MSP"), "added airport")
+
+	utils.AssertEqual(t, nil, LoadAirlines(strings.NewReader("# airlines\nGA\n\nxt\n")))
+	utils.AssertEqual(t, true, IsAirline("XT"), "loaded airline")
+	utils.AssertEqual(t, false, IsAirline("QZ"), "airline outside loaded codes")
+
+	SetAirlines()
+	utils.AssertEqual(t, true, IsAirline("QZ"), "reset to format only")
+}
+
+func TestIsCardNumber(t *testing.T) {
+	utils.AssertEqual(t, true, IsCardNumber("4111111111111111"))
+	utils.AssertEqual(t, true, IsCardNumber("4111 1111 1111 1111"))
+	utils.AssertEqual(t, true, IsCardNumber("5500-0000-0000-0004"))
+	utils.AssertEqual(t, true, IsCardNumber("378282246310005"))
+	utils.AssertEqual(t, false, IsCardNumber("4111111111111112"), "checksum")
+	utils.AssertEqual(t, false, IsCardNumber("41111111"), "too short")
+	utils.AssertEqual(t, false, IsCardNumber("4111a11111111111"), "letters")
+}
+
+func TestRegister(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	type booking struct {
+		Origin      string          `json:"origin" validate:"iata_airport"`
+		Carrier     string          `json:"carrier" validate:"iata_airline"`
+		Currency    string          `json:"currency" validate:"iso_currency"`
+		Nationality string          `json:"nationality" validate:"iso_country"`
+		BookingCode string          `json:"booking_code" validate:"omitempty,pnr"`
+		Passport    string          `json:"passport" validate:"passport_number"`
+		ExpiryDate  time.Time       `json:"expiry_date" validate:"passport_expiry"`
+		IssuedUntil *strfmt.Date    `json:"issued_until" validate:"omitempty,passport_expiry=3"`
+		Expiry      string          `json:"expiry" validate:"omitempty,passport_expiry=0"`
+		ValidUntil  strfmt.DateTime `json:"valid_until" validate:"omitempty,passport_expiry=12"`
+		CardNumber  string          `json:"card_number" validate:"omitempty,card_number"`
+	}
+
+	validBooking := booking{
+		Origin:      "CGK",
+		Carrier:     "GA",
+		Currency:    "IDR",
+		Nationality: "ID",
+		BookingCode: "X7K9QD",
+		Passport:    "A1234567",
+		ExpiryDate:  time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+		CardNumber:  "4111 1111 1111 1111",
+	}
+	utils.AssertEqual(t, nil, lib.VALIDATOR.Struct(validBooking), "registered on lib.VALIDATOR")
+
+	date := strfmt.Date(time.Date(2024, 4, 14, 0, 0, 0, 0, time.UTC))
+	invalidBooking := booking{
+		Origin:      "XY1",
+		Carrier:     "G@",
+		Currency:    "RUP",
+		Nationality: "IDN",
+		BookingCode: "x7k9qd",
+		Passport:    "A12",
+		ExpiryDate:  time.Date(2024, 7, 14, 0, 0, 0, 0, time.UTC),
+		IssuedUntil: &date,
+		Expiry:      "2024-01-14",
+		ValidUntil:  strfmt.DateTime(time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC)),
+		CardNumber:  "4111111111111112",
+	}
+	errs := lib.VALIDATOR.Struct(invalidBooking).(validator.ValidationErrors)
+	tags := map[string]string{}
+	for _, err := range errs {
+		tags[err.Field()] = err.Tag()
+	}
+	utils.AssertEqual(t, map[string]string{
+		"origin":       TagAirport,
+		"carrier":      TagAirline,
+		"currency":     TagCurrency,
+		"nationality":  TagCountry,
+		"booking_code": TagPNR,
+		"passport":     TagPassportNumber,
+		"expiry_date":  TagPassportExpiry,
+		"issued_until": TagPassportExpiry,
+		"expiry":       TagPassportExpiry,
+		"valid_until":  TagPassportExpiry,
+		"card_number":  TagCardNumber,
+	}, tags)
+
+	for _, tag := range []string{TagAirport, TagAirline, TagCurrency, TagCountry, TagPNR, TagPassportNumber, TagPassportExpiry, TagCardNumber} {
+		for _, lang := range []string{"en", "id"} {
+			message := lib.ValidationMessage(lang, lib.ErrorData{Name: "field", Validator: tag})
+			utils.AssertEqual(t, true, message != lib.ValidationMessage(lang, lib.ErrorData{Name: "field", Validator: "default"}), lang+" message of "+tag)
+		}
+	}
+}